@@ -1,6 +1,7 @@
 package main
 
 import (
+	"os"
 	"strings"
 
 	"github.com/taylorchu/generic/rewrite"
@@ -30,10 +31,17 @@ func NewConfig(pkgPath, newPkgPath string, rules ...string) (*ConfigV1, error) {
 	return c, nil
 }
 
+// RewritePackage rewrites to the current directory, and uses $GOPACKAGE as the package name of local specs.
 func (c1 *ConfigV1) RewritePackage() error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
 	spec := rewrite.Spec(*c1)
 	c := rewrite.Config{
-		Spec: []*rewrite.Spec{&spec},
+		Dir:         dir,
+		PackageName: os.Getenv("GOPACKAGE"),
+		Spec:        []*rewrite.Spec{&spec},
 	}
 	return c.RewritePackage()
 }
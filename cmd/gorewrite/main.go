@@ -1,14 +1,42 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"io/ioutil"
 	"log"
+	"os"
+	"sort"
 
 	"github.com/taylorchu/generic/rewrite"
 	yaml "gopkg.in/yaml.v2"
 )
 
+var (
+	dryRun  = flag.Bool("dry-run", false, "rewrite without writing any file, and list files that would change")
+	diff    = flag.Bool("diff", false, "print a unified diff of files that would change; implies -dry-run")
+	check   = flag.Bool("check", false, "exit with non-zero status if any generated file is stale, missing or extra")
+	workers = flag.Int("workers", 0, "how many specs are rewritten at the same time; overrides workers in GoRewrite.yaml")
+)
+
 func main() {
+	flag.Parse()
+
+	switch flag.Arg(0) {
+	case "origin":
+		origin(flag.Args()[1:])
+		return
+	case "constraints":
+		constraints(flag.Args()[1:])
+		return
+	case "migrate":
+		migrate(flag.Args()[1:])
+		return
+	case "":
+	default:
+		log.Fatalf("unknown command %q", flag.Arg(0))
+	}
+
 	b, err := ioutil.ReadFile("GoRewrite.yaml")
 	if err != nil {
 		log.Fatalln(err)
@@ -19,9 +47,119 @@ func main() {
 	if err != nil {
 		log.Fatalln(err)
 	}
+	if *workers > 0 {
+		c.Workers = *workers
+	}
+	// The library does not read the current directory or environment, so they are defaults of the command.
+	if c.Dir == "" {
+		c.Dir = cwd()
+	}
+	if c.PackageName == "" {
+		c.PackageName = os.Getenv("GOPACKAGE")
+	}
+
+	if *check {
+		err = c.Check()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	if *dryRun || *diff {
+		changes, err := c.DryRun()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		var spec string
+		for _, ch := range changes {
+			if *diff {
+				if ch.Spec != spec {
+					spec = ch.Spec
+					fmt.Printf("# spec %s\n", spec)
+				}
+				err = ch.WriteDiff(os.Stdout)
+			} else {
+				_, err = fmt.Println(ch)
+			}
+			if err != nil {
+				log.Fatalln(err)
+			}
+		}
+		return
+	}
 
 	err = c.RewritePackage()
 	if err != nil {
 		log.Fatalln(err)
 	}
 }
+
+// origin prints which spec and template produced a generated file.
+func origin(args []string) {
+	if len(args) != 1 {
+		log.Fatalln("gorewrite origin [FILE]")
+	}
+	b, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	o, err := rewrite.ReadOrigin(b)
+	if err != nil {
+		log.Fatalf("%s: %v", args[0], err)
+	}
+	fmt.Printf("spec: %s\n", o.Spec)
+	switch {
+	case o.Import != "":
+		fmt.Printf("import: %s\n", o.Import)
+	case o.Template != "":
+		fmt.Printf("template: %s\n", o.Template)
+	}
+	fmt.Printf("source: %s\n", o.Source)
+	fmt.Println("typeMap:")
+	var names []string
+	for name := range o.TypeMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		to := o.TypeMap[name]
+		fmt.Printf("  %s: %s\n", name, to.Expr)
+		for _, im := range to.Import {
+			fmt.Printf("    import: %s\n", im)
+		}
+	}
+}
+
+// constraints prints what replacements of type placeholders in a template package must be.
+func constraints(args []string) {
+	if len(args) != 1 {
+		log.Fatalln("gorewrite constraints [IMPORT]")
+	}
+	cs, err := rewrite.InferConstraints(cwd(), args[0])
+	if err != nil {
+		log.Fatalf("%s: %v", args[0], err)
+	}
+	for _, c := range cs {
+		fmt.Println(c)
+	}
+}
+
+// migrate converts type placeholders in a template package to type parameters.
+func migrate(args []string) {
+	if len(args) != 2 {
+		log.Fatalln("gorewrite migrate [IMPORT] [DIR]")
+	}
+	err := rewrite.Migrate(cwd(), args[0], args[1])
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func cwd() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	return dir
+}
@@ -0,0 +1,7 @@
+package GOPACKAGE
+
+type Data int
+
+func resultNew() *Data {
+	return new(Data)
+}
@@ -0,0 +1,16 @@
+package GOPACKAGE
+
+import (
+	"strconv"
+	"time"
+)
+
+type Int64 int64
+
+func (i Int64) String() string {
+	return strconv.FormatInt(int64(i), 10)
+}
+
+func (i Int64) Round(m Int64) time.Duration {
+	return time.Duration(i / m * m)
+}
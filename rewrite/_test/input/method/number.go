@@ -0,0 +1,7 @@
+package GOPACKAGE
+
+type Number int
+
+func (Number) func1() {}
+func (Number) func2() {}
+func (Number) func3() {}
@@ -0,0 +1,9 @@
+package GOPACKAGE
+
+type Data struct{}
+
+type Key []string
+
+type Score float64
+
+type Name string
@@ -1,3 +1,3 @@
 package result
 
-type Type int
+const old = 1
@@ -0,0 +1,7 @@
+package container
+
+type Type int
+
+type TypeContainer struct {
+	Val Type
+}
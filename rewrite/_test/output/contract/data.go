@@ -0,0 +1,38 @@
+package GOPACKAGE
+
+import (
+	"io"
+	"strconv"
+)
+
+type Data int
+
+func (d Data) Less(other Data) bool {
+	return d < other
+}
+
+func (d Data) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, strconv.Itoa(int(d)))
+	return int64(n), err
+}
+
+type BadData int
+
+func (d BadData) Less(other int) bool {
+	return int(d) < other
+}
+
+func (d BadData) WriteTo(w io.Writer) (int64, error) {
+	return 0, nil
+}
+
+type Value string
+
+func (v Value) String() string {
+	return string(v)
+}
+
+func (v *Value) Set(s string) error {
+	*v = Value(s)
+	return nil
+}
@@ -0,0 +1,4 @@
+package GOPACKAGE
+
+// resultKeep is not generated, so it is kept.
+type resultKeep Data
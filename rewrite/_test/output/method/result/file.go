@@ -1,6 +0,0 @@
-package result
-
-import "github.com/taylorchu/generic/rewrite/_test/pkg/vendoring"
-
-func (Type2 Type) Type2(_ vendoring.Number, _ vendoring.Number) {
-}
@@ -0,0 +1,53 @@
+package migrate
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// Entry is a key-value entry with a rank.
+type Entry[Type cmp.Ordered, TypeKey fmt.Stringer, TypeValue interface{ Len() int }] struct {
+	Key   TypeKey
+	Value TypeValue
+	Rank  Type
+}
+
+// Table is a table of entries.
+type Table[Type cmp.Ordered, TypeKey fmt.Stringer, TypeValue interface{ Len() int }] struct {
+	entries map[string][]Entry[Type, TypeKey, TypeValue]
+}
+
+// NewTable creates an empty table.
+func NewTable[Type cmp.Ordered, TypeKey fmt.Stringer, TypeValue interface{ Len() int }]() *Table[Type, TypeKey, TypeValue] {
+	return &Table[Type, TypeKey, TypeValue]{entries: make(map[string][]Entry[Type, TypeKey, TypeValue])}
+}
+
+// Add adds an entry.
+func (t *Table[Type, TypeKey, TypeValue]) Add(e Entry[Type, TypeKey, TypeValue]) {
+	k := e.Key.String()
+	t.entries[k] = append(t.entries[k], e)
+}
+
+// Best returns the entry with the highest rank.
+func (t *Table[Type, TypeKey, TypeValue]) Best(key TypeKey) (Entry[Type, TypeKey, TypeValue], bool) {
+	return best[Type, TypeKey, TypeValue](t.entries[key.String()])
+}
+
+func best[Type cmp.Ordered, TypeKey fmt.Stringer, TypeValue interface{ Len() int }](entries []Entry[Type, TypeKey, TypeValue]) (Entry[Type, TypeKey, TypeValue], bool) {
+	var b Entry[Type, TypeKey, TypeValue]
+	for i, e := range entries {
+		if i == 0 || b.Rank < e.Rank {
+			b = e
+		}
+	}
+	return b, len(entries) > 0
+}
+
+// Size returns the total length of values.
+func Size[TypeValue interface{ Len() int }](values []TypeValue) int {
+	n := 0
+	for _, v := range values {
+		n += v.Len()
+	}
+	return n
+}
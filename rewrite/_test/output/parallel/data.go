@@ -0,0 +1,3 @@
+package GOPACKAGE
+
+type Data int
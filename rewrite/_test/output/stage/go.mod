@@ -0,0 +1,7 @@
+module example.com/consumer
+
+go 1.18
+
+require example.com/template v0.0.0
+
+replace example.com/template => ../_test/module
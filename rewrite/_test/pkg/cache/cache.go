@@ -0,0 +1,36 @@
+package cache
+
+// Cache is a map that evicts the oldest key when it is full.
+type Cache[K comparable, V any] struct {
+	items map[K]V
+	keys  []K
+	size  int
+}
+
+// New creates a cache that holds up to size keys.
+func New[K comparable, V any](size int) *Cache[K, V] {
+	return &Cache[K, V]{items: make(map[K]V), size: size}
+}
+
+// Get returns the value of k.
+func (c *Cache[K, V]) Get(k K) (V, bool) {
+	v, ok := c.items[k]
+	return v, ok
+}
+
+// Set sets the value of k.
+func (c *Cache[K, V]) Set(k K, v V) {
+	if _, ok := c.items[k]; !ok {
+		if len(c.keys) == c.size {
+			delete(c.items, c.keys[0])
+			c.keys = c.keys[1:]
+		}
+		c.keys = append(c.keys, k)
+	}
+	c.items[k] = v
+}
+
+// Keys returns keys from the oldest to the newest.
+func Keys[K comparable, V any](c *Cache[K, V]) []K {
+	return append([]K(nil), c.keys...)
+}
@@ -0,0 +1,26 @@
+// Copyright 2017 The Generic Authors. All rights reserved.
+
+// Package comment is a list of Type values.
+package comment
+
+// Type is the element type.
+type Type int
+
+// String is only here for testing.
+func (Type) String() string { return "" } // String is removed with Type.
+
+// TypeList holds items of Type.
+//
+// Use [TypeList.Append] to add a [Type], and see [TypeList] for more.
+type TypeList struct {
+	items []Type // items are Type values.
+}
+
+/*
+Append adds v to the end of the list.
+It is not related to Types or MyType.
+*/
+func (l *TypeList) Append(v Type) {
+	// Append to the underlying slice.
+	l.items = append(l.items, v)
+}
@@ -0,0 +1,47 @@
+package contract
+
+import "io"
+
+// Type can be ordered, and written out.
+type Type interface {
+	Less(Type) bool
+	io.WriterTo
+}
+
+// TypeValue is a flag value.
+type TypeValue int
+
+// String is part of the contract of TypeValue.
+func (v TypeValue) String() string {
+	return ""
+}
+
+// Set is also part of the contract, but only for *TypeValue.
+func (v *TypeValue) Set(s string) error {
+	return nil
+}
+
+// Min returns the smaller one of a and b.
+func Min(a, b Type) Type {
+	if b.Less(a) {
+		return b
+	}
+	return a
+}
+
+// Write writes v to w.
+func Write(w io.Writer, v Type) error {
+	_, err := v.WriteTo(w)
+	return err
+}
+
+// Flag is a flag with a default value.
+type Flag struct {
+	Value   TypeValue
+	Default string
+}
+
+// Reset sets the value to the default.
+func (f *Flag) Reset() error {
+	return f.Value.Set(f.Default)
+}
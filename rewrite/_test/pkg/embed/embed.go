@@ -0,0 +1,34 @@
+package embed
+
+type Type int
+
+// TypeBox wraps a Type value.
+type TypeBox struct {
+	Type
+	Count int
+}
+
+// NewBox makes a TypeBox of v.
+func NewBox(v Type) TypeBox {
+	return TypeBox{Type: v, Count: 1}
+}
+
+// Get returns the Type value.
+func (b TypeBox) Get() Type {
+	return b.Type
+}
+
+// Ref refers to a Type value.
+type Ref struct {
+	*Type
+}
+
+// NewRef makes a Ref to v.
+func NewRef(v *Type) Ref {
+	return Ref{v}
+}
+
+// Get returns the Type value.
+func (r Ref) Get() *Type {
+	return r.Type
+}
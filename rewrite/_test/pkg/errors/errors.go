@@ -0,0 +1,10 @@
+package errors
+
+// Error is an error with a code.
+type Error struct {
+	Code int
+}
+
+func (e *Error) Error() string {
+	return "error"
+}
@@ -0,0 +1,15 @@
+package expr
+
+type Type interface{}
+
+// From converts v to Type.
+func From(v Type) Type {
+	return Type(v)
+}
+
+// Send sends v to a new channel of Type values.
+func Send(v Type) chan Type {
+	ch := make(chan Type, 1)
+	ch <- v
+	return ch
+}
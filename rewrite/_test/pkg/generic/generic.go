@@ -0,0 +1,55 @@
+package generic
+
+import "cmp"
+
+// Queue is a FIFO queue.
+type Queue[T any] struct {
+	items []T
+}
+
+// NewQueue creates an empty queue.
+func NewQueue[T any]() *Queue[T] {
+	return &Queue[T]{}
+}
+
+// Of creates a queue with vs.
+func Of[T any](vs ...T) *Queue[T] {
+	q := NewQueue[T]()
+	for _, v := range vs {
+		q.Push(v)
+	}
+	return q
+}
+
+// Push adds v to the end.
+func (q *Queue[T]) Push(v T) {
+	q.items = append(q.items, v)
+}
+
+// Pop removes the first value.
+func (q *Queue[E]) Pop() (E, bool) {
+	var zero E
+	if len(q.items) == 0 {
+		return zero, false
+	}
+	v := q.items[0]
+	q.items = q.items[1:]
+	return v, true
+}
+
+// Max returns the largest value in q.
+func Max[T cmp.Ordered](q *Queue[T]) T {
+	var max T
+	for i, v := range q.items {
+		if i == 0 || v > max {
+			max = v
+		}
+	}
+	return max
+}
+
+// Pair is a key-value pair.
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
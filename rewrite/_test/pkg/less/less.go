@@ -0,0 +1,13 @@
+package less
+
+type Type int
+
+func (t Type) Less(other Type) bool { return t < other }
+
+// Min returns the smaller one of a and b.
+func Min(a, b Type) Type {
+	if b.Less(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,66 @@
+package migrate
+
+import "fmt"
+
+// Type is a rank.
+type Type int
+
+// TypeKey is a key.
+type TypeKey interface {
+	fmt.Stringer
+}
+
+// TypeValue is a value.
+type TypeValue string
+
+// Len is part of the contract of TypeValue.
+func (v TypeValue) Len() int {
+	return len(v)
+}
+
+// Entry is a key-value entry with a rank.
+type Entry struct {
+	Key   TypeKey
+	Value TypeValue
+	Rank  Type
+}
+
+// Table is a table of entries.
+type Table struct {
+	entries map[string][]Entry
+}
+
+// NewTable creates an empty table.
+func NewTable() *Table {
+	return &Table{entries: make(map[string][]Entry)}
+}
+
+// Add adds an entry.
+func (t *Table) Add(e Entry) {
+	k := e.Key.String()
+	t.entries[k] = append(t.entries[k], e)
+}
+
+// Best returns the entry with the highest rank.
+func (t *Table) Best(key TypeKey) (Entry, bool) {
+	return best(t.entries[key.String()])
+}
+
+func best(entries []Entry) (Entry, bool) {
+	var b Entry
+	for i, e := range entries {
+		if i == 0 || b.Rank < e.Rank {
+			b = e
+		}
+	}
+	return b, len(entries) > 0
+}
+
+// Size returns the total length of values.
+func Size(values []TypeValue) int {
+	n := 0
+	for _, v := range values {
+		n += v.Len()
+	}
+	return n
+}
@@ -0,0 +1,36 @@
+package operator
+
+type Type int
+
+type TypeKey string
+
+// Max returns the larger of a and b.
+func Max(a, b Type) Type {
+	if a < b {
+		return b
+	}
+	return a
+}
+
+// Sum adds up all values.
+func Sum(vs []Type) Type {
+	var sum Type
+	for _, v := range vs {
+		sum += v
+	}
+	return sum
+}
+
+// Count counts how many times each key appears.
+func Count(keys []TypeKey) map[TypeKey]int {
+	m := make(map[TypeKey]int)
+	for _, k := range keys {
+		m[k]++
+	}
+	return m
+}
+
+// FromInt converts n to Type.
+func FromInt(n int) Type {
+	return Type(n)
+}
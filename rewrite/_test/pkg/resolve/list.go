@@ -0,0 +1,17 @@
+package resolve
+
+type Type int
+
+// TypeList is a list of Type items.
+type TypeList []Type
+
+// Len returns the number of Type items.
+func (l TypeList) Len() int {
+	return len(l)
+}
+
+// Pair has a field that is named after the type placeholder.
+type Pair struct {
+	Type  string
+	Value Type
+}
@@ -0,0 +1,48 @@
+package resolve
+
+// NewList makes a TypeList of items.
+func NewList(items ...Type) TypeList {
+	return TypeList(items)
+}
+
+// Lens returns the length of each list.
+func Lens(lists []TypeList) []int {
+	length := TypeList.Len
+	var lens []int
+	for _, l := range lists {
+		lens = append(lens, length(l))
+	}
+	return lens
+}
+
+// Index maps Type items by the name of their pairs.
+func Index(pairs []Pair) map[string]Type {
+	m := map[string]Type{}
+	for _, p := range pairs {
+		m[p.Type] = p.Value
+	}
+	return m
+}
+
+// First returns the first item.
+func First(l TypeList) Type {
+	return Apply[Type](l[0], func(v Type) Type { return v })
+}
+
+// Apply calls f with v.
+func Apply[T any](v T, f func(T) T) T {
+	return f(v)
+}
+
+// Names returns names that are unrelated to the type placeholder.
+func Names() []string {
+	Type := "shadowed"
+	p := Pair{Type: Type}
+	return []string{p.Type}
+}
+
+// Kinds returns names of a local type that shares its name with the type placeholder.
+func Kinds() []string {
+	type Type string
+	return []string{string(Type("local"))}
+}
@@ -0,0 +1,45 @@
+package unused
+
+import (
+	_ "embed"
+	. "math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type is a number.
+type Type int64
+
+// String formats t.
+func (t Type) String() string {
+	return strconv.FormatInt(int64(t), 10)
+}
+
+// Round rounds t to a multiple of m.
+func (t Type) Round(m Type) time.Duration {
+	return time.Duration(t / m * m)
+}
+
+type (
+	// TypeKey identifies a value.
+	TypeKey string
+
+	// Pair holds a value with its key.
+	Pair struct {
+		Key   TypeKey
+		Value Type
+	}
+)
+
+// Limit is the largest number of pairs.
+const Limit = MaxInt16
+
+// Join formats values of pairs.
+func Join(pairs []Pair) string {
+	var s []string
+	for _, p := range pairs {
+		s = append(s, p.Value.String())
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,41 @@
+package rewrite
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckError lists generated files that are not up to date.
+type CheckError struct {
+	Changes []*Change
+}
+
+func (e *CheckError) Error() string {
+	var b strings.Builder
+	b.WriteString("generated files are not up to date:")
+	for _, ch := range e.Changes {
+		state := "stale"
+		switch {
+		case ch.Old == nil:
+			state = "missing"
+		case ch.New == nil:
+			state = "extra"
+		}
+		fmt.Fprintf(&b, "\n\t%s: %s (spec %s)", state, ch.Path, ch.Spec)
+	}
+	return b.String()
+}
+
+// Check rewrites all specs in memory, and compares the output with files on disk.
+//
+// It returns *CheckError if any generated file is stale, missing or extra.
+func (c *Config) Check() error {
+	changes, err := c.DryRun()
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		return &CheckError{Changes: changes}
+	}
+	return nil
+}
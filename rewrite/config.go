@@ -1,5 +1,18 @@
 package rewrite
 
+import (
+	"context"
+	"errors"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
 type Type struct {
 	Expr   string
 	Import []string
@@ -10,37 +23,308 @@ type Spec struct {
 
 	Name   string
 	Import string
+	// Template is the directory of the package that it is rewritten from, relative to Config.Dir. It cannot be absolute.
+	// It is used instead of Import for templates that cannot be imported.
+	Template string
+	// FS and Source provide the package that it is rewritten from instead of Import, like templates embedded in a binary.
+	// FS has files of the package at its root, and Source maps file names to their content.
+	FS     fs.FS             `yaml:"-"`
+	Source map[string]string `yaml:"-"`
 	Local  bool
+	// Partial keeps type parameters that are not in typeMap.
+	Partial bool
+	// Before and After are custom passes that run before and after built-in passes of this spec.
+	Before []Pass `yaml:"-"`
+	After  []Pass `yaml:"-"`
+	// Output is the directory that the spec is written to, relative to Config.Dir.
+	// If it is empty, it is the spec name, or Config.Dir itself if the spec is local.
+	Output string
 }
 
 type Config struct {
 	Spec []*Spec
+	// Workers is how many specs are rewritten at the same time.
+	// If it is not positive, GOMAXPROCS is used.
+	Workers int
+	// Dir is the base directory. Imports are resolved from it, and output is written relative to it.
+	Dir string
+	// PackageName is the package name of local specs.
+	PackageName string `yaml:"packageName"`
+	// Before and After are custom passes that run before and after built-in passes of every spec.
+	Before []Pass `yaml:"-"`
+	After  []Pass `yaml:"-"`
 }
 
+// RewritePackage rewrites all specs, and writes their output to Dir only if all of them succeed.
 func (c *Config) RewritePackage() error {
-	for _, s := range c.Spec {
-		pkg, err := s.parse()
+	return c.Write(context.Background(), &DiskSink{Dir: c.Dir})
+}
+
+// Write rewrites all specs, and passes their output to sink only if all of them succeed.
+func (c *Config) Write(ctx context.Context, sink Sink) error {
+	st, err := c.rewrite(ctx)
+	if err != nil {
+		return err
+	}
+	return sink.Write(ctx, st.output())
+}
+
+// Generate rewrites all specs, and returns formatted files by path relative to Dir without writing anything.
+func (c *Config) Generate(ctx context.Context) (map[string][]byte, error) {
+	st, err := c.rewrite(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return st.output().Files, nil
+}
+
+// errSkipped is returned by a spec that is not rewritten because its dependency fails.
+var errSkipped = errors.New("skipped")
+
+// rewrite rewrites all specs to stage without writing anything.
+//
+// Specs are rewritten concurrently, but a spec waits for specs that it depends on.
+// Errors of all specs are returned together.
+func (c *Config) rewrite(ctx context.Context) (*stage, error) {
+	if c.Dir == "" {
+		return nil, errors.New("config: dir cannot be empty")
+	}
+	// go/packages only accepts absolute paths in overlays.
+	dir, err := filepath.Abs(c.Dir)
+	if err != nil {
+		return nil, err
+	}
+	st := newStage(dir)
+	resolver := newImportResolver(dir)
+
+	workers := c.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	sem := make(chan struct{}, workers)
+	done := make([]chan struct{}, len(c.Spec))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	errs := make([]error, len(c.Spec))
+
+	var wg sync.WaitGroup
+	for i, s := range c.Spec {
+		deps := c.dependencies(i)
+		// Files of other local specs might be rewritten concurrently, so they are hidden from this spec.
+		hidden := make(map[string]struct{})
+		for j, other := range c.Spec {
+			if j != i && other.Local && other.Name != s.Name {
+				hidden[other.Name] = struct{}{}
+			}
+		}
+		for _, j := range deps {
+			delete(hidden, c.Spec[j].Name)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[i])
+			for _, j := range deps {
+				<-done[j]
+				if errs[j] != nil {
+					errs[i] = errSkipped
+					return
+				}
+			}
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				errs[i] = errSkipped
+				return
+			}
+			errs[i] = s.rewrite(ctx, c, st, resolver, hidden)
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var errReport []error
+	for _, err := range errs {
+		if err != nil && err != errSkipped {
+			errReport = append(errReport, err)
+		}
+	}
+	if len(errReport) > 0 {
+		return nil, errors.Join(errReport...)
+	}
+
+	// Specs are reported in the order of config.
+	order := make(map[string]int)
+	for i := len(c.Spec) - 1; i >= 0; i-- {
+		order[c.Spec[i].Name] = i
+	}
+	sort.SliceStable(st.specs, func(i, j int) bool {
+		return order[st.specs[i].name] < order[st.specs[j].name]
+	})
+	return st, nil
+}
+
+// dependencies returns earlier specs that the i-th spec depends on.
+//
+// A spec depends on another spec if it imports the output of the other spec,
+// or if both are local in the same directory and its typeMap might use top-level identifiers of the other spec.
+func (c *Config) dependencies(i int) []int {
+	s := c.Spec[i]
+	imports := []string{s.Import}
+	if s.Template != "" {
+		imports = append(imports, filepath.ToSlash(filepath.Clean(s.Template)))
+	}
+	for _, to := range s.TypeMap {
+		for _, im := range to.Import {
+			_, path := splitImport(im)
+			imports = append(imports, path)
+		}
+		imports = append(imports, qualifiedImports(to.Expr)...)
+	}
+
+	var deps []int
+	for j, other := range c.Spec[:i] {
+		var dep bool
+		for _, path := range imports {
+			out := filepath.ToSlash(filepath.Clean(other.outputDir()))
+			if path == out || strings.HasSuffix(path, "/"+out) {
+				dep = true
+			}
+		}
+		if s.Local && other.Local && s.outputDir() == other.outputDir() {
+			// Top-level identifiers of the other spec are either prefixed, or named after its typeMap.
+			prefix := lintName(other.Name + "_")
+			names := make(map[string]struct{})
+			for _, to := range other.TypeMap {
+				for _, word := range commentWord.FindAllString(to.Expr, -1) {
+					if types.Universe.Lookup(word) == nil {
+						names[word] = struct{}{}
+					}
+				}
+			}
+			for _, to := range s.TypeMap {
+				for _, word := range commentWord.FindAllString(to.Expr, -1) {
+					if _, ok := names[word]; ok || strings.HasPrefix(word, prefix) {
+						dep = true
+					}
+				}
+			}
+		}
+		if dep {
+			deps = append(deps, j)
+		}
+	}
+	return deps
+}
+
+// rewrite rewrites the spec to stage.
+//
+// Custom passes of the config run before those of the spec.
+// Imports of typeMap are inferred with resolver, which is shared by specs of the config.
+func (s *Spec) rewrite(ctx context.Context, c *Config, st *stage, resolver *importResolver, hidden map[string]struct{}) error {
+	err := s.checkOutput()
+	if err != nil {
+		return err
+	}
+	configured := s
+	importer := newImporter(ctx, token.NewFileSet(), st.dir, st.overlay())
+	s = s.qualifyTypeMap(importer)
+	err = s.checkTypeMap()
+	if err != nil {
+		return err
+	}
+	s, err = s.inferImports(ctx, importer, resolver)
+	if err != nil {
+		return err
+	}
+	err = s.checkTypeImports(importer)
+	if err != nil {
+		return err
+	}
+	pkg, err := s.parse(ctx, st.dir)
+	if err != nil {
+		return err
+	}
+	s, err = s.resolveImportConflicts(pkg, importer)
+	if err != nil {
+		return err
+	}
+	before := append(append([]Pass(nil), c.Before...), s.Before...)
+	after := append(append([]Pass(nil), c.After...), s.After...)
+
+	runBefore := func(pkg *Package) error {
+		if len(before) == 0 {
+			return nil
+		}
+		importPath := pkg.types.Path()
+		err := s.runPasses(pkg, before)
 		if err != nil {
 			return err
 		}
-		resetAST := func(pkg *Package) error {
-			return pkg.Reset()
-		}
-
-		// Apply AST changes and refresh.
-		for _, rewriteFunc := range []func(*Package) error{
-			s.rewritePackageName,
-			s.removePlaceholder,
-			s.rewriteIdent,
-			s.prefixTopLevelDecl,
-			resetAST,
-			s.typeCheck,
-			s.writePackage,
-		} {
-			err := rewriteFunc(pkg)
-			if err != nil {
-				return err
-			}
+		// Built-in passes need type information of whatever the passes add.
+		err = pkg.Reset()
+		if err != nil {
+			return err
+		}
+		checkTemplate(ctx, st.dir, importPath, pkg)
+		return nil
+	}
+	declared, err := s.localDecls(st, pkg, hidden)
+	if err != nil {
+		return err
+	}
+	removePlaceholder := func(pkg *Package) error {
+		return s.removePlaceholder(pkg, declared)
+	}
+	rewritePackageName := func(pkg *Package) error {
+		return s.rewritePackageName(pkg, c.PackageName)
+	}
+	resetAST := func(pkg *Package) error {
+		return pkg.Reset()
+	}
+	typeCheck := func(pkg *Package) error {
+		return s.typeCheck(ctx, st, pkg, hidden)
+	}
+	runAfter := func(pkg *Package) error {
+		if len(after) == 0 {
+			return nil
+		}
+		err := s.runPasses(pkg, after)
+		if err != nil {
+			return err
+		}
+		err = pkg.Reset()
+		if err != nil {
+			return err
+		}
+		return s.typeCheck(ctx, st, pkg, hidden)
+	}
+	writePackage := func(pkg *Package) error {
+		return s.writePackage(st, pkg, configured)
+	}
+
+	// Apply AST changes and refresh.
+	for _, rewriteFunc := range []func(*Package) error{
+		runBefore,
+		s.monomorphize,
+		rewritePackageName,
+		removePlaceholder,
+		s.rewriteEmbeddedField,
+		s.rewriteIdent,
+		s.prefixTopLevelDecl,
+		s.removeUnusedImports,
+		resetAST,
+		typeCheck,
+		runAfter,
+		writePackage,
+	} {
+		err := rewriteFunc(pkg)
+		if err != nil {
+			return err
 		}
 	}
 	return nil
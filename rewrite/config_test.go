@@ -1,6 +1,18 @@
 package rewrite
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
 
 func TestRewritePackage(t *testing.T) {
 	c := &Config{Spec: []*Spec{
@@ -35,13 +47,14 @@ func TestRewritePackageMethod(t *testing.T) {
 	c := &Config{Spec: []*Spec{
 		{
 			Name:   "result",
+			Local:  true,
 			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/method",
 			TypeMap: map[string]Type{
-				"Type2": Type{Expr: "vendoring.Number", Import: []string{"github.com/taylorchu/generic/rewrite/_test/pkg/vendoring"}},
+				"Type2": Type{Expr: "Number"},
 			},
 		},
 	}}
-	testRewritePackage(t, c, "_test/output/method")
+	testRewritePackageWithInput(t, c, "_test/input/method", "_test/output/method")
 }
 
 func TestRewritePackageInternal(t *testing.T) {
@@ -157,3 +170,1424 @@ func TestRewritePackageRenameUnresolvedLocal(t *testing.T) {
 	}}
 	testRewritePackageWithInput(t, c, "_test/input/data_unresolved", "_test/output/rename_unresolved_local")
 }
+
+func TestRewritePackageComment(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/comment",
+			TypeMap: map[string]Type{
+				"Type":     Type{Expr: "*time.Duration", Import: []string{"time"}},
+				"TypeList": Type{Expr: "List"},
+			},
+		},
+	}}
+	testRewritePackage(t, c, "_test/output/comment")
+}
+
+func TestRewritePackageModule(t *testing.T) {
+	defer os.Setenv("GO111MODULE", os.Getenv("GO111MODULE"))
+	os.Setenv("GO111MODULE", "on")
+
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Import: "example.com/template/queue",
+			TypeMap: map[string]Type{
+				"Type":      Type{Expr: "int64"},
+				"TypeQueue": Type{Expr: "FIFO"},
+			},
+		},
+	}}
+	testRewritePackageWithInput(t, c, "_test/input/module", "_test/output/module")
+}
+
+func TestRewritePackageSharedImport(t *testing.T) {
+	// time.Duration of context and time, and fs.FileMode of os and io/fs are the same types.
+	source := map[string]string{
+		"timeout.go": `package timeout
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"time"
+)
+
+type Type interface{}
+
+// WithTimeout returns a context that is canceled after a second, and v.
+func WithTimeout(ctx context.Context, v Type) (context.Context, context.CancelFunc, Type) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	return ctx, cancel, v
+}
+
+// Mode is the mode of new files.
+var Mode fs.FileMode = os.ModePerm
+`,
+	}
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Source: source,
+			TypeMap: map[string]Type{
+				"Type": Type{Expr: "time.Duration"},
+			},
+		},
+	}}
+	testRewritePackage(t, c, "_test/output/shared_import")
+}
+
+func TestRewritePackageLocalTypeCheck(t *testing.T) {
+	for _, test := range []struct {
+		input   string
+		spec    *Spec
+		wantErr []string
+	}{
+		{
+			input: "_test/input/data_conflict",
+			spec: &Spec{
+				Name:   "result",
+				Local:  true,
+				Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+				TypeMap: map[string]Type{
+					"Type":      Type{Expr: "Data"},
+					"TypeQueue": Type{Expr: "FIFO"},
+				},
+			},
+			wantErr: []string{"resultNew redeclared in this block"},
+		},
+		{
+			input: "_test/input/data",
+			spec: &Spec{
+				Name:   "result",
+				Local:  true,
+				Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+				TypeMap: map[string]Type{
+					"Type":      Type{Expr: "Dta"},
+					"TypeQueue": Type{Expr: "FIFO"},
+				},
+			},
+			wantErr: []string{"result_queue.go:", "undefined: Dta"},
+		},
+		{
+			input: "_test/input/data",
+			spec: &Spec{
+				Name:   "result",
+				Local:  true,
+				Import: "github.com/taylorchu/generic/rewrite/_test/pkg/less",
+				TypeMap: map[string]Type{
+					"Type": Type{Expr: "Data"},
+				},
+			},
+			wantErr: []string{"result_less.go:5:7: b.Less undefined"},
+		},
+	} {
+		testRewritePackageError(t, &Config{Spec: []*Spec{test.spec}}, test.input, test.wantErr...)
+	}
+}
+
+func TestRewritePackageStage(t *testing.T) {
+	defer os.Setenv("GO111MODULE", os.Getenv("GO111MODULE"))
+	os.Setenv("GO111MODULE", "on")
+
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Import: "example.com/template/queue",
+			TypeMap: map[string]Type{
+				"Type":      Type{Expr: "int64"},
+				"TypeQueue": Type{Expr: "FIFO"},
+			},
+		},
+		{
+			Name:   "box",
+			Import: "example.com/template/container",
+			TypeMap: map[string]Type{
+				"Type":          Type{Expr: "*result.FIFO", Import: []string{"example.com/consumer/result"}},
+				"TypeContainer": Type{Expr: "Box"},
+			},
+		},
+		{
+			Name:   "local",
+			Local:  true,
+			Import: "example.com/template/queue",
+			TypeMap: map[string]Type{
+				"Type":      Type{Expr: "Data"},
+				"TypeQueue": Type{Expr: "FIFO"},
+			},
+		},
+		{
+			Name:   "local_box",
+			Local:  true,
+			Import: "example.com/template/container",
+			TypeMap: map[string]Type{
+				"Type":          Type{Expr: "*FIFO"},
+				"TypeContainer": Type{Expr: "Box"},
+			},
+		},
+	}}
+	testRewritePackageWithInput(t, c, "_test/input/module", "_test/output/stage")
+
+	// Staged output is found in the same way if dir is relative.
+	testOutput(t, "_test/input/module", inDir(c, func(c *Config) error {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		c.Dir, err = filepath.Rel(wd, c.Dir)
+		if err != nil {
+			return err
+		}
+		return c.RewritePackage()
+	}), "_test/output/stage")
+}
+
+func TestRewritePackageStageError(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type":      Type{Expr: "int64"},
+				"TypeQueue": Type{Expr: "FIFO"},
+			},
+		},
+		{
+			Name:   "local",
+			Local:  true,
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type":      Type{Expr: "Dta"},
+				"TypeQueue": Type{Expr: "FIFO"},
+			},
+		},
+	}}
+	testRewritePackageError(t, c, "_test/input/data", "undefined: Dta")
+}
+
+func TestRewritePackageParallel(t *testing.T) {
+	c := &Config{Workers: 2}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		c.Spec = append(c.Spec, &Spec{
+			Name:   name,
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type":      Type{Expr: "int64"},
+				"TypeQueue": Type{Expr: "FIFO"},
+			},
+		})
+	}
+	for _, name := range []string{"local_a", "local_b"} {
+		c.Spec = append(c.Spec, &Spec{
+			Name:   name,
+			Local:  true,
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type": Type{Expr: "Data"},
+			},
+		})
+	}
+	testRewritePackageWithInput(t, c, "_test/input/data", "_test/output/parallel")
+}
+
+func TestRewritePackageParallelError(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "a",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type": Type{Expr: "Dta"},
+			},
+		},
+		{
+			Name:   "b",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type": Type{Expr: "int64"},
+			},
+		},
+		{
+			Name:   "c",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type": Type{Expr: "Dat"},
+			},
+		},
+		{
+			// This depends on a, so it is skipped.
+			Name:   "d",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type": Type{Expr: "a.TypeQueue", Import: []string{"a"}},
+			},
+		},
+	}}
+	err := runInDir(t, "_test/input/data", inDir(c, (*Config).RewritePackage))
+	want := "a/queue.go:5:10: undefined: Dta\n" +
+		"a/queue.go:14:29: undefined: Dta\n" +
+		"a/queue.go:20:27: undefined: Dta\n" +
+		"a/queue.go:10:34: undefined: Dta\n" +
+		"c/queue.go:5:10: undefined: Dat\n" +
+		"c/queue.go:14:29: undefined: Dat\n" +
+		"c/queue.go:20:27: undefined: Dat\n" +
+		"c/queue.go:10:34: undefined: Dat"
+	if err == nil || err.Error() != want {
+		t.Fatalf("expect error:\n%s\ngot:\n%v", want, err)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type":      Type{Expr: "int64"},
+				"TypeQueue": Type{Expr: "FIFO"},
+			},
+		},
+		{
+			Name:   "box",
+			Local:  true,
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/container",
+			TypeMap: map[string]Type{
+				"Type":          Type{Expr: "*Data"},
+				"TypeContainer": Type{Expr: "Box"},
+			},
+		},
+	}}
+	changes := testDryRun(t, c, "_test/input/stale",
+		"result: update result/queue.go",
+		"result: remove result/old.go",
+		"box: add box_def.go",
+	)
+
+	buf := new(bytes.Buffer)
+	err := changes[0].WriteDiff(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `--- a/result/queue.go
++++ b/result/queue.go
+@@ -9,7 +9,7 @@
+ 
+ package result
+ 
+-// FIFO is a queue of int64 types.
++// FIFO represents a queue of int64 types.
+ type FIFO struct {
+ 	items []int64
+ }
+`
+	if buf.String() != expect {
+		t.Fatalf("expect diff:\n%s\ngot:\n%s", expect, buf)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type":      Type{Expr: "int64"},
+				"TypeQueue": Type{Expr: "FIFO"},
+			},
+		},
+		{
+			Name:   "box",
+			Local:  true,
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/container",
+			TypeMap: map[string]Type{
+				"Type":          Type{Expr: "*Data"},
+				"TypeContainer": Type{Expr: "Box"},
+			},
+		},
+	}}
+	testError(t, "_test/input/stale", inDir(c, (*Config).Check),
+		"stale: result/queue.go (spec result)",
+		"extra: result/old.go (spec result)",
+		"missing: box_def.go (spec box)",
+	)
+
+	c.Spec = c.Spec[:1]
+	err := runInDir(t, "_test/output/queue", inDir(c, (*Config).Check))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRewritePackageLocalStale(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Local:  true,
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/container",
+			TypeMap: map[string]Type{
+				"Type":          Type{Expr: "*Data"},
+				"TypeContainer": Type{Expr: "Box"},
+			},
+		},
+	}}
+	testRewritePackageWithInput(t, c, "_test/input/local_stale", "_test/output/local_stale")
+}
+
+func TestRewritePackageContract(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Local:  true,
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/contract",
+			TypeMap: map[string]Type{
+				"Type":      Type{Expr: "Data"},
+				"TypeValue": Type{Expr: "Value"},
+			},
+		},
+	}}
+	testRewritePackageWithInput(t, c, "_test/input/contract", "_test/output/contract")
+}
+
+func TestRewritePackageContractError(t *testing.T) {
+	for _, test := range []struct {
+		input   string
+		typeMap map[string]Type
+		wantErr []string
+	}{
+		{
+			input: "_test/input/contract",
+			typeMap: map[string]Type{
+				"Type":      Type{Expr: "int"},
+				"TypeValue": Type{Expr: "Value"},
+			},
+			wantErr: []string{"spec result: int does not satisfy placeholder Type: missing method Less(int) bool"},
+		},
+		{
+			input: "_test/input/contract",
+			typeMap: map[string]Type{
+				"Type":      Type{Expr: "BadData"},
+				"TypeValue": Type{Expr: "Value"},
+			},
+			wantErr: []string{"spec result: BadData does not satisfy placeholder Type: wrong type for method Less: have Less(other int) bool, want Less(BadData) bool"},
+		},
+		{
+			input: "_test/input/contract",
+			typeMap: map[string]Type{
+				"Type":      Type{Expr: "Data"},
+				"TypeValue": Type{Expr: "Data"},
+			},
+			wantErr: []string{
+				"spec result: Data does not satisfy placeholder TypeValue: missing method String() string",
+				"spec result: *Data does not satisfy placeholder TypeValue: missing method Set(s string) error",
+			},
+		},
+		{
+			input: "_test/input/data",
+			typeMap: map[string]Type{
+				"Type2": Type{Expr: "vendoring.Number", Import: []string{"github.com/taylorchu/generic/rewrite/_test/pkg/vendoring"}},
+			},
+			wantErr: []string{"spec result: vendoring.Number does not satisfy placeholder Type2: missing method func1()"},
+		},
+	} {
+		s := &Spec{
+			Name:    "result",
+			Local:   true,
+			Import:  "github.com/taylorchu/generic/rewrite/_test/pkg/contract",
+			TypeMap: test.typeMap,
+		}
+		if _, ok := test.typeMap["Type2"]; ok {
+			s.Local = false
+			s.Import = "github.com/taylorchu/generic/rewrite/_test/pkg/method"
+		}
+		testRewritePackageError(t, &Config{Spec: []*Spec{s}}, test.input, test.wantErr...)
+	}
+}
+
+func TestRewritePackageRenameInterface(t *testing.T) {
+	source := map[string]string{
+		"iface.go": `package iface
+
+// TypeIter iterates over values.
+type TypeIter interface {
+	Next() bool
+}
+
+// Drain counts values left in it.
+func Drain(it TypeIter) int {
+	var n int
+	for it.Next() {
+		n++
+	}
+	return n
+}
+`,
+	}
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Source: source,
+			TypeMap: map[string]Type{
+				"TypeIter": Type{Expr: "Iter"},
+			},
+		},
+	}}
+	testRewritePackage(t, c, "_test/output/rename_interface")
+}
+
+func TestRewritePackageOperator(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Local:  true,
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/operator",
+			TypeMap: map[string]Type{
+				"Type":    Type{Expr: "Score"},
+				"TypeKey": Type{Expr: "Name"},
+			},
+		},
+	}}
+	testRewritePackageWithInput(t, c, "_test/input/operator", "_test/output/operator")
+}
+
+func TestRewritePackageOperatorError(t *testing.T) {
+	for _, test := range []struct {
+		typeMap map[string]Type
+		wantErr []string
+	}{
+		{
+			typeMap: map[string]Type{
+				"Type":    Type{Expr: "Data"},
+				"TypeKey": Type{Expr: "Name"},
+			},
+			wantErr: []string{
+				"spec result: Data does not satisfy placeholder Type: Data is not ordered, but Type is used with < at operator.go:9:5",
+				"spec result: Data does not satisfy placeholder Type: Data is not numeric or string, but Type is used with + at operator.go:19:3",
+				"spec result: Data does not satisfy placeholder Type: Data is not convertible from int, but Type is converted from int at operator.go:35:9",
+			},
+		},
+		{
+			typeMap: map[string]Type{
+				"Type":    Type{Expr: "Score"},
+				"TypeKey": Type{Expr: "Key"},
+			},
+			wantErr: []string{
+				"spec result: Key does not satisfy placeholder TypeKey: Key is not comparable, but TypeKey is used as a map key at operator.go:25:32",
+			},
+		},
+	} {
+		s := &Spec{
+			Name:    "result",
+			Local:   true,
+			Import:  "github.com/taylorchu/generic/rewrite/_test/pkg/operator",
+			TypeMap: test.typeMap,
+		}
+		testRewritePackageError(t, &Config{Spec: []*Spec{s}}, "_test/input/operator", test.wantErr...)
+	}
+}
+
+func TestInferConstraints(t *testing.T) {
+	constraints, err := InferConstraints(".", "github.com/taylorchu/generic/rewrite/_test/pkg/operator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"Type must be ordered: used with < at operator.go:9:5",
+		"Type must be numeric or string: used with + at operator.go:19:3",
+		"TypeKey must be comparable: used as a map key at operator.go:25:32",
+		"Type must be convertible from int: converted from int at operator.go:35:9",
+	}
+	if len(constraints) != len(want) {
+		t.Fatalf("expect %d constraints, got %v", len(want), constraints)
+	}
+	for i, c := range constraints {
+		if c.String() != want[i] {
+			t.Fatalf("expect %q, got %q", want[i], c.String())
+		}
+	}
+}
+
+func TestRewritePackageGeneric(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/generic",
+			TypeMap: map[string]Type{
+				"Queue.T":    Type{Expr: "int64"},
+				"NewQueue.T": Type{Expr: "int64"},
+				"Of.T":       Type{Expr: "int64"},
+				"Max.T":      Type{Expr: "int64"},
+				"Pair.K":     Type{Expr: "string"},
+				"Pair.V":     Type{Expr: "time.Duration", Import: []string{"time"}},
+			},
+		},
+	}}
+	testRewritePackage(t, c, "_test/output/generic")
+}
+
+func TestRewritePackageGenericError(t *testing.T) {
+	for _, test := range []struct {
+		typeMap map[string]Type
+		wantErr []string
+	}{
+		{
+			typeMap: map[string]Type{
+				"Pair.K": Type{Expr: "string"},
+			},
+			wantErr: []string{"spec result: type parameter Pair.V is not in typeMap"},
+		},
+		{
+			typeMap: map[string]Type{
+				"Stack.T": Type{Expr: "int64"},
+			},
+			wantErr: []string{"spec result: Stack.T is not a type parameter"},
+		},
+		{
+			typeMap: map[string]Type{
+				"Queue.T":    Type{Expr: "bool"},
+				"NewQueue.T": Type{Expr: "bool"},
+				"Of.T":       Type{Expr: "bool"},
+				"Max.T":      Type{Expr: "bool"},
+				"Pair.K":     Type{Expr: "[]byte"},
+				"Pair.V":     Type{Expr: "bool"},
+			},
+			wantErr: []string{
+				"spec result: bool does not satisfy placeholder Max.T: bool is not in cmp.Ordered",
+				"spec result: []byte does not satisfy placeholder Pair.K: []byte is not comparable",
+			},
+		},
+	} {
+		c := &Config{Spec: []*Spec{
+			{
+				Name:    "result",
+				Import:  "github.com/taylorchu/generic/rewrite/_test/pkg/generic",
+				TypeMap: test.typeMap,
+			},
+		}}
+		testRewritePackageError(t, c, "_test/input/data", test.wantErr...)
+	}
+}
+
+func TestRewritePackageTypeArg(t *testing.T) {
+	source := map[string]string{
+		"names.go": `package names
+
+// Queue is a FIFO queue.
+type Queue[T any] struct {
+	items []T
+}
+
+// Names returns an empty queue of names.
+func Names() *Queue[string] {
+	return &Queue[string]{}
+}
+
+// Bytes returns an empty queue of bytes.
+func Bytes() *Queue[[]byte] {
+	return &Queue[[]uint8]{}
+}
+`,
+	}
+	for _, test := range []struct {
+		typeMap map[string]Type
+		wantErr []string
+	}{
+		{
+			typeMap: map[string]Type{
+				"Queue.T": Type{Expr: "int64"},
+			},
+			wantErr: []string{
+				"spec result: Queue[string] is instantiated with string, but Queue.T is int64",
+				"spec result: Queue[[]byte] is instantiated with []byte, but Queue.T is int64",
+			},
+		},
+		{
+			typeMap: map[string]Type{
+				"Queue.T": Type{Expr: "string"},
+			},
+			wantErr: []string{"spec result: Queue[[]byte] is instantiated with []byte, but Queue.T is string"},
+		},
+	} {
+		c := &Config{Spec: []*Spec{
+			{
+				Name:    "result",
+				Source:  source,
+				TypeMap: test.typeMap,
+			},
+		}}
+		testRewritePackageError(t, c, "_test/input/data", test.wantErr...)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	testOutput(t, "", func(dir string) error {
+		return Migrate(dir, "github.com/taylorchu/generic/rewrite/_test/pkg/migrate", "result")
+	}, "_test/output/migrate")
+}
+
+func TestMigrateError(t *testing.T) {
+	for _, test := range []struct {
+		importPath string
+		outputDir  string
+		wantErr    string
+	}{
+		{
+			importPath: "github.com/taylorchu/generic/rewrite/_test/pkg/contract",
+			outputDir:  "result",
+			wantErr:    "migrate github.com/taylorchu/generic/rewrite/_test/pkg/contract: method Set of type placeholder TypeValue has a pointer receiver",
+		},
+		{
+			importPath: "github.com/taylorchu/generic/rewrite/_test/pkg/vendoring",
+			outputDir:  "result",
+			wantErr:    "migrate github.com/taylorchu/generic/rewrite/_test/pkg/vendoring: type placeholder is not found",
+		},
+		{
+			importPath: "github.com/taylorchu/generic/rewrite/_test/pkg/migrate",
+			outputDir:  ".",
+			wantErr:    "migrate github.com/taylorchu/generic/rewrite/_test/pkg/migrate: output . cannot be ",
+		},
+		{
+			importPath: "github.com/taylorchu/generic/rewrite/_test/pkg/migrate",
+			outputDir:  "../result",
+			wantErr:    "migrate github.com/taylorchu/generic/rewrite/_test/pkg/migrate: output ../result must be inside ",
+		},
+	} {
+		testError(t, "_test/input/data", func(dir string) error {
+			return Migrate(dir, test.importPath, test.outputDir)
+		}, test.wantErr)
+	}
+}
+
+func TestRewritePackagePartial(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:    "result",
+			Import:  "github.com/taylorchu/generic/rewrite/_test/pkg/cache",
+			Partial: true,
+			TypeMap: map[string]Type{
+				"Cache.K": Type{Expr: "string"},
+				"New.K":   Type{Expr: "string"},
+				"Keys.K":  Type{Expr: "string"},
+			},
+		},
+	}}
+	testRewritePackage(t, c, "_test/output/partial")
+}
+
+func TestRewritePackagePartialError(t *testing.T) {
+	for _, test := range []struct {
+		partial bool
+		typeMap map[string]Type
+		wantErr []string
+	}{
+		{
+			typeMap: map[string]Type{
+				"Cache.K": Type{Expr: "string"},
+				"New.K":   Type{Expr: "string"},
+				"Keys.K":  Type{Expr: "string"},
+			},
+			wantErr: []string{"spec result: type parameter Cache.V is not in typeMap"},
+		},
+		{
+			partial: true,
+			typeMap: map[string]Type{
+				"Cache.K": Type{Expr: "[]byte"},
+				"New.K":   Type{Expr: "[]byte"},
+				"Keys.K":  Type{Expr: "[]byte"},
+			},
+			wantErr: []string{"spec result: []byte does not satisfy placeholder Cache.K: []byte is not comparable"},
+		},
+		{
+			partial: true,
+			typeMap: map[string]Type{
+				"Cache.K": Type{Expr: "string"},
+				"New.K":   Type{Expr: "int"},
+			},
+			wantErr: []string{"result/cache.go:"},
+		},
+	} {
+		c := &Config{Spec: []*Spec{
+			{
+				Name:    "result",
+				Import:  "github.com/taylorchu/generic/rewrite/_test/pkg/cache",
+				Partial: test.partial,
+				TypeMap: test.typeMap,
+			},
+		}}
+		testRewritePackageError(t, c, "_test/input/data", test.wantErr...)
+	}
+}
+
+func TestRewritePackageOutput(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Output: "gen/queue",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type":      Type{Expr: "int64"},
+				"TypeQueue": Type{Expr: "FIFO"},
+			},
+		},
+		{
+			Name:   "local",
+			Local:  true,
+			Output: "sub",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type":      Type{Expr: "Data"},
+				"TypeQueue": Type{Expr: "FIFO"},
+			},
+		},
+	}}
+	testRewritePackageWithInput(t, c, "_test/input/output", "_test/output/output")
+}
+
+func TestRewritePackageOutputError(t *testing.T) {
+	for _, test := range []struct {
+		spec    *Spec
+		wantErr string
+	}{
+		{
+			spec:    &Spec{Name: "result", Output: "."},
+			wantErr: "spec result: output . cannot be the config directory, because it is replaced as a whole",
+		},
+		{
+			spec:    &Spec{Name: "./"},
+			wantErr: "spec ./: name cannot be the config directory, because its output is replaced as a whole",
+		},
+		{
+			spec:    &Spec{Name: "result", Output: "../result"},
+			wantErr: "spec result: output ../result must be inside the config directory",
+		},
+		{
+			spec:    &Spec{Name: "result", Output: "/tmp/result"},
+			wantErr: "spec result: output /tmp/result must be inside the config directory",
+		},
+		{
+			spec:    &Spec{Name: "local", Local: true, Output: ".."},
+			wantErr: "spec local: output .. must be inside the config directory",
+		},
+	} {
+		test.spec.Import = "github.com/taylorchu/generic/rewrite/_test/pkg/queue"
+		test.spec.TypeMap = map[string]Type{
+			"Type":      Type{Expr: "Data"},
+			"TypeQueue": Type{Expr: "FIFO"},
+		}
+		testRewritePackageError(t, &Config{Spec: []*Spec{test.spec}}, "_test/input/output", test.wantErr)
+	}
+}
+
+func TestRewritePackageConfigError(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "local",
+			Local:  true,
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type": Type{Expr: "Data"},
+			},
+		},
+	}}
+	err := c.RewritePackage()
+	if err == nil || err.Error() != "config: dir cannot be empty" {
+		t.Fatalf("expect empty dir error, got %v", err)
+	}
+	testError(t, "_test/input/data", func(dir string) error {
+		c.Dir = dir
+		return c.RewritePackage()
+	}, "spec local: package name of local specs cannot be empty")
+}
+
+func TestGenerate(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Local:  true,
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/method",
+			TypeMap: map[string]Type{
+				"Type2": Type{Expr: "Number"},
+			},
+		},
+	}}
+	var files map[string][]byte
+	err := runInDir(t, "_test/input/method", inDir(c, func(c *Config) error {
+		var err error
+		files, err = c.Generate(context.Background())
+		return err
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, expect := sortedKeys(files), []string{"result_def.go", "result_file.go"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expect %q, got %q", expect, got)
+	}
+	for path, b := range files {
+		expect, err := os.ReadFile(filepath.Join("_test/output/method", path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, expect) {
+			t.Fatalf("expect %s:\n%s\ngot:\n%s", path, expect, b)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = runInDir(t, "_test/input/method", inDir(c, func(c *Config) error {
+		_, err := c.Generate(ctx)
+		return err
+	}))
+	if err != context.Canceled {
+		t.Fatalf("expect %v, got %v", context.Canceled, err)
+	}
+}
+
+// testSink records output instead of writing it.
+type testSink struct {
+	out *Output
+}
+
+func (sink *testSink) Write(ctx context.Context, out *Output) error {
+	sink.out = out
+	return nil
+}
+
+func TestWriteSink(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Local:  true,
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/container",
+			TypeMap: map[string]Type{
+				"Type":          Type{Expr: "*Data"},
+				"TypeContainer": Type{Expr: "Box"},
+			},
+		},
+		{
+			Name:   "queue",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type":      Type{Expr: "int64"},
+				"TypeQueue": Type{Expr: "FIFO"},
+			},
+		},
+	}}
+	sink := new(testSink)
+	err := runInDir(t, "_test/input/local_stale", inDir(c, func(c *Config) error {
+		return c.Write(context.Background(), sink)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		got, expect []string
+	}{
+		{sortedKeys(sink.out.Files), []string{"queue/queue.go", "result_def.go"}},
+		{sink.out.Dirs, []string{"queue"}},
+		{sink.out.Remove, []string{"result_old.go"}},
+	} {
+		if !reflect.DeepEqual(test.got, test.expect) {
+			t.Fatalf("expect %q, got %q", test.expect, test.got)
+		}
+	}
+}
+
+func TestRewritePackageTemplate(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:     "result",
+			Template: "templates/queue",
+			TypeMap: map[string]Type{
+				"Type":      Type{Expr: "int64"},
+				"TypeQueue": Type{Expr: "FIFO"},
+			},
+		},
+		{
+			Name: "embedded",
+			FS:   os.DirFS("_test/pkg/queue"),
+			TypeMap: map[string]Type{
+				"Type":      Type{Expr: "string"},
+				"TypeQueue": Type{Expr: "Names"},
+			},
+		},
+		{
+			Name: "source",
+			Source: map[string]string{
+				"join.go": `package join
+
+import "strings"
+
+type Type string
+
+// Join joins Type items with sep.
+func Join(items []Type, sep string) string {
+	var b strings.Builder
+	for i, item := range items {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(string(item))
+	}
+	return b.String()
+}
+`,
+				"join_test.go": `package join
+
+import "testing"
+
+func TestJoin(t *testing.T) {}
+`,
+			},
+			TypeMap: map[string]Type{
+				"Type": Type{Expr: "[]byte"},
+			},
+		},
+	}}
+	testRewritePackageWithInput(t, c, "_test/input/template", "_test/output/template")
+}
+
+func TestRewritePackageTemplateError(t *testing.T) {
+	absTemplate, err := filepath.Abs("_test/input/template/templates/queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		spec    *Spec
+		wantErr string
+	}{
+		{
+			spec:    &Spec{Name: "result"},
+			wantErr: "spec result: import cannot be empty",
+		},
+		{
+			spec: &Spec{
+				Name:     "result",
+				Import:   "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+				Template: "templates/queue",
+			},
+			wantErr: "spec result: only one of import, template, fs and source can be set",
+		},
+		{
+			spec:    &Spec{Name: "result", Template: "templates/missing"},
+			wantErr: "spec result: ",
+		},
+		{
+			spec:    &Spec{Name: "result", Template: absTemplate},
+			wantErr: "spec result: template " + absTemplate + " must be relative to the config directory",
+		},
+	} {
+		c := &Config{Spec: []*Spec{test.spec}}
+		testRewritePackageError(t, c, "_test/input/template", test.wantErr)
+	}
+}
+
+func TestRewritePackagePass(t *testing.T) {
+	var seen []string
+	c := &Config{
+		Spec: []*Spec{
+			{
+				Name:   "result",
+				Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+				TypeMap: map[string]Type{
+					"Type":      Type{Expr: "int64"},
+					"TypeQueue": Type{Expr: "FIFO"},
+				},
+				Before: []Pass{PassFunc(func(s *Spec, pkg *Package) error {
+					// Add a function that uses a type placeholder, so it is rewritten too.
+					src := fmt.Sprintf("package %s\n\nfunc Zero() Type {\n\tvar z Type\n\treturn z\n}\n", pkg.Types().Name())
+					f, err := parser.ParseFile(pkg.FileSet, "zero.go", src, parser.ParseComments)
+					if err != nil {
+						return err
+					}
+					pkg.Files["zero.go"] = f
+					return nil
+				})},
+				After: []Pass{PassFunc(func(s *Spec, pkg *Package) error {
+					// Add String methods to structs.
+					src := new(bytes.Buffer)
+					fmt.Fprintf(src, "package %s\n\nimport \"fmt\"\n", pkg.Types().Name())
+					scope := pkg.Types().Scope()
+					for _, name := range scope.Names() {
+						obj := scope.Lookup(name)
+						if _, ok := obj.Type().Underlying().(*types.Struct); ok {
+							fmt.Fprintf(src, "\nfunc (x *%s) String() string {\n\treturn fmt.Sprint(*x)\n}\n", name)
+						}
+					}
+					f, err := parser.ParseFile(pkg.FileSet, "string.go", src, parser.ParseComments)
+					if err != nil {
+						return err
+					}
+					pkg.Files["string.go"] = f
+					return nil
+				})},
+			},
+		},
+		Before: []Pass{PassFunc(func(s *Spec, pkg *Package) error {
+			seen = append(seen, "before "+pkg.Types().Scope().Lookup("TypeQueue").Type().String())
+			return nil
+		})},
+		After: []Pass{PassFunc(func(s *Spec, pkg *Package) error {
+			seen = append(seen, "after "+pkg.Types().Scope().Lookup("FIFO").Type().String())
+			return nil
+		})},
+	}
+	testRewritePackage(t, c, "_test/output/pass")
+
+	expect := []string{
+		"before github.com/taylorchu/generic/rewrite/_test/pkg/queue.TypeQueue",
+		"after FIFO",
+	}
+	if !reflect.DeepEqual(seen, expect) {
+		t.Fatalf("expect %q, got %q", expect, seen)
+	}
+}
+
+func TestRewritePackagePassError(t *testing.T) {
+	// Exported structs must be documented.
+	lint := PassFunc(func(s *Spec, pkg *Package) error {
+		for _, f := range pkg.Files {
+			for _, decl := range f.Decls {
+				decl, ok := decl.(*ast.GenDecl)
+				if !ok || decl.Tok != token.TYPE || decl.Doc != nil {
+					continue
+				}
+				for _, spec := range decl.Specs {
+					obj := pkg.TypesInfo().Defs[spec.(*ast.TypeSpec).Name]
+					if _, ok := obj.Type().Underlying().(*types.Struct); ok && obj.Exported() {
+						return fmt.Errorf("%s is not documented", obj.Name())
+					}
+				}
+			}
+		}
+		return nil
+	})
+	c := &Config{
+		Spec: []*Spec{
+			{
+				Name: "result",
+				Source: map[string]string{
+					"box.go": `package box
+
+type Type int
+
+type TypeBox struct {
+	v Type
+}
+`,
+				},
+				TypeMap: map[string]Type{
+					"Type":    Type{Expr: "int64"},
+					"TypeBox": Type{Expr: "Box"},
+				},
+			},
+		},
+		After: []Pass{lint},
+	}
+	testRewritePackageError(t, c, "_test/input/data", "spec result: Box is not documented")
+}
+
+func TestRewritePackageResolve(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "result",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/resolve",
+			TypeMap: map[string]Type{
+				"Type":     Type{Expr: "int64"},
+				"TypeList": Type{Expr: "Int64s"},
+			},
+		},
+		{
+			Name:   "local",
+			Local:  true,
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/resolve",
+			TypeMap: map[string]Type{
+				"Type":     Type{Expr: "Data"},
+				"TypeList": Type{Expr: "DataList"},
+			},
+		},
+	}}
+	testRewritePackageWithInput(t, c, "_test/input/data", "_test/output/resolve")
+}
+
+func TestRewritePackageEmbeddedField(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "duration",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/embed",
+			TypeMap: map[string]Type{
+				"Type":    Type{Expr: "time.Duration", Import: []string{"time"}},
+				"TypeBox": Type{Expr: "DurationBox"},
+			},
+		},
+		{
+			Name:   "slice",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/embed",
+			TypeMap: map[string]Type{
+				"Type":    Type{Expr: "[]int"},
+				"TypeBox": Type{Expr: "SliceBox"},
+			},
+		},
+		{
+			Name:   "pointer",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/embed",
+			TypeMap: map[string]Type{
+				"Type":    Type{Expr: "*big.Int", Import: []string{"math/big"}},
+				"TypeBox": Type{Expr: "IntBox"},
+			},
+		},
+	}}
+	testRewritePackage(t, c, "_test/output/embedded_field")
+}
+
+func TestRewritePackageEmbeddedFieldError(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name: "result",
+			Source: map[string]string{
+				"named.go": `package named
+
+type Type interface {
+	String() string
+}
+
+// TypeNamed has a name.
+type TypeNamed struct {
+	Type
+}
+
+// Describe describes the name.
+func (n TypeNamed) Describe() string {
+	return "name: " + n.String()
+}
+`,
+			},
+			TypeMap: map[string]Type{
+				"Type":      Type{Expr: "[]fmt.Stringer", Import: []string{"fmt"}},
+				"TypeNamed": Type{Expr: "Named"},
+			},
+		},
+	}}
+	testRewritePackageError(t, c, "_test/input/data",
+		"spec result: []fmt.Stringer cannot be embedded in place of Type, but String is promoted from it at named.go:14:22")
+}
+
+func TestRewritePackageTypeExpr(t *testing.T) {
+	var specs []*Spec
+	for name, expr := range map[string]Type{
+		"pointer":  Type{Expr: "*big.Int", Import: []string{"math/big"}},
+		"function": Type{Expr: "func() int"},
+		"receive":  Type{Expr: "<-chan int"},
+	} {
+		specs = append(specs, &Spec{
+			Name:    name,
+			Import:  "github.com/taylorchu/generic/rewrite/_test/pkg/expr",
+			TypeMap: map[string]Type{"Type": expr},
+		})
+	}
+	specs = append(specs, &Spec{
+		Name: "method",
+		Source: map[string]string{
+			"method.go": `package method
+
+type Type interface {
+	String() string
+}
+
+// Stringer returns the String method of Type.
+func Stringer() func(Type) string {
+	return Type.String
+}
+`,
+		},
+		TypeMap: map[string]Type{
+			"Type": Type{Expr: "*big.Int", Import: []string{"math/big"}},
+		},
+	})
+	testRewritePackage(t, &Config{Spec: specs}, "_test/output/type_expr")
+}
+
+func TestRewritePackageTypeExprError(t *testing.T) {
+	for _, test := range []struct {
+		typeMap map[string]Type
+		wantErr string
+	}{
+		{
+			typeMap: map[string]Type{"Type": Type{Expr: "map[string"}},
+			wantErr: "spec result: typeMap Type: 1:11: expected ']', found newline",
+		},
+		{
+			typeMap: map[string]Type{"Type": Type{Expr: "1 + 2"}},
+			wantErr: "spec result: typeMap Type: 1 + 2 is not a type",
+		},
+		{
+			typeMap: map[string]Type{
+				"Type":      Type{Expr: "int64"},
+				"TypeQueue": Type{Expr: "[]int64"},
+			},
+			wantErr: "spec result: typeMap TypeQueue: []int64 is declared in the package, so it can only be renamed to an identifier",
+		},
+	} {
+		c := &Config{Spec: []*Spec{
+			{
+				Name:    "result",
+				Import:  "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+				TypeMap: test.typeMap,
+			},
+		}}
+		testRewritePackageError(t, c, "_test/input/data", test.wantErr)
+	}
+}
+
+func TestRewritePackageTypeImportError(t *testing.T) {
+	for _, test := range []struct {
+		local   bool
+		to      Type
+		wantErr string
+	}{
+		{
+			to:      Type{Expr: "time.Duraton", Import: []string{"time"}},
+			wantErr: "spec result: typeMap Type: time.Duraton is not found in time; candidates: time.Duration, ",
+		},
+		{
+			local:   true,
+			to:      Type{Expr: "[]*time.Duraton", Import: []string{"time"}},
+			wantErr: "spec result: typeMap Type: time.Duraton is not found in time; candidates: time.Duration, ",
+		},
+		{
+			to:      Type{Expr: "time.Now", Import: []string{"time"}},
+			wantErr: "spec result: typeMap Type: time.Now is a function, not a type in time",
+		},
+		{
+			to:      Type{Expr: "time.UTC", Import: []string{"time"}},
+			wantErr: "spec result: typeMap Type: time.UTC is a variable, not a type in time",
+		},
+		{
+			to:      Type{Expr: "map[string]nosuchpkg.Type"},
+			wantErr: "spec result: typeMap Type: nosuchpkg.Type is used, but package nosuchpkg is not in import",
+		},
+		{
+			to:      Type{Expr: "missing.Type", Import: []string{"example.com/missing"}},
+			wantErr: "spec result: typeMap Type: import example.com/missing: ",
+		},
+	} {
+		c := &Config{Spec: []*Spec{
+			{
+				Name:   "result",
+				Local:  test.local,
+				Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+				TypeMap: map[string]Type{
+					"Type": test.to,
+				},
+			},
+		}}
+		testRewritePackageError(t, c, "_test/input/data", test.wantErr)
+	}
+}
+
+func TestRewritePackageInferImport(t *testing.T) {
+	var specs []*Spec
+	for name, to := range map[string]Type{
+		"duration": Type{Expr: "time.Duration"},
+		"random":   Type{Expr: "*rand.Rand"},
+		"randomv1": Type{Expr: "*rand.Rand", Import: []string{"math/rand"}},
+		"mixed":    Type{Expr: "map[template.HTML]*big.Int", Import: []string{"html/template"}},
+	} {
+		specs = append(specs, &Spec{
+			Name:   name,
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type":      to,
+				"TypeQueue": Type{Expr: "FIFO"},
+			},
+		})
+	}
+	testRewritePackage(t, &Config{Spec: specs}, "_test/output/infer_import")
+}
+
+func TestRewritePackageQualifiedType(t *testing.T) {
+	var specs []*Spec
+	for name, to := range map[string]Type{
+		"vendoring": Type{Expr: "*github.com/taylorchu/generic/rewrite/_test/pkg/vendoring.Number"},
+		"collision": Type{Expr: "map[html/template.HTML]*text/template.Template"},
+		"explicit":  Type{Expr: "map[template.HTML]text/template.FuncMap", Import: []string{"html/template"}},
+	} {
+		specs = append(specs, &Spec{
+			Name:   name,
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+			TypeMap: map[string]Type{
+				"Type":      to,
+				"TypeQueue": Type{Expr: "FIFO"},
+			},
+		})
+	}
+	testRewritePackage(t, &Config{Spec: specs}, "_test/output/qualified_type")
+}
+
+func TestRewritePackageImportConflict(t *testing.T) {
+	source := map[string]string{
+		"conflict.go": `package conflict
+
+import "errors"
+
+type Type interface{}
+
+type Num interface{}
+
+// Check returns v, or an error if v is missing.
+func Check(v Type, ok bool) (Type, error) {
+	if !ok {
+		return v, errors.New("missing")
+	}
+	return v, nil
+}
+
+// Same returns n in a variable that is named like a package.
+func Same(n Num) Num {
+	big := n
+	return big
+}
+`,
+	}
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "conflict",
+			Source: source,
+			TypeMap: map[string]Type{
+				"Type": Type{Expr: "*errors.Error", Import: []string{"github.com/taylorchu/generic/rewrite/_test/pkg/errors"}},
+				"Num":  Type{Expr: "*big.Int"},
+			},
+		},
+		{
+			Name:   "alias",
+			Source: source,
+			TypeMap: map[string]Type{
+				"Type": Type{Expr: "*e.Error", Import: []string{"e github.com/taylorchu/generic/rewrite/_test/pkg/errors"}},
+				"Num":  Type{Expr: "*bigint.Int", Import: []string{"bigint math/big"}},
+			},
+		},
+	}}
+	testRewritePackage(t, c, "_test/output/import_conflict")
+}
+
+func TestRewritePackageUnusedImport(t *testing.T) {
+	c := &Config{Spec: []*Spec{
+		{
+			Name:   "duration",
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/unused",
+			TypeMap: map[string]Type{
+				"Type":    Type{Expr: "time.Duration", Import: []string{"time"}},
+				"TypeKey": Type{Expr: "string"},
+			},
+		},
+		{
+			Name:   "number",
+			Local:  true,
+			Import: "github.com/taylorchu/generic/rewrite/_test/pkg/unused",
+			TypeMap: map[string]Type{
+				"Type":    Type{Expr: "Int64"},
+				"TypeKey": Type{Expr: "string"},
+			},
+		},
+	}}
+	testRewritePackageWithInput(t, c, "_test/input/int64", "_test/output/unused_import")
+}
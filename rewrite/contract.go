@@ -0,0 +1,252 @@
+package rewrite
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// contract is what the replacement of a removed type placeholder must satisfy.
+//
+// A type placeholder declares it as an interface, or with its methods.
+// Constraints are inferred from how the type placeholder is used.
+// A type parameter declares it as its constraint.
+type contract struct {
+	name string
+	// iface is satisfied by the replacement, and ptrIface by a pointer to it.
+	iface, ptrIface *ast.InterfaceType
+	constraints     []*Constraint
+	// typeParamConstraint is the constraint of a type parameter.
+	typeParamConstraint ast.Expr
+	// imports are from files where the contract is declared.
+	imports []*ast.ImportSpec
+	// typeArgs are explicit type arguments of a type parameter, which must be identical to the replacement.
+	typeArgs []*typeArg
+}
+
+// typeArg is an explicit type argument of an instantiation that is converted.
+type typeArg struct {
+	expr ast.Expr
+	// inst and arg are how the instantiation and the type argument are written in the package, like Queue[string].
+	inst, arg string
+}
+
+// addMethod adds a method that is declared on the type placeholder.
+func (c *contract) addMethod(node *ast.File, decl *ast.FuncDecl) {
+	iface := &c.iface
+	if _, ok := decl.Recv.List[0].Type.(*ast.StarExpr); ok {
+		iface = &c.ptrIface
+	}
+	if *iface == nil {
+		*iface = &ast.InterfaceType{Methods: &ast.FieldList{}}
+	}
+	(*iface).Methods.List = append((*iface).Methods.List, &ast.Field{
+		Names: []*ast.Ident{decl.Name},
+		Type:  decl.Type,
+	})
+	c.imports = append(c.imports, node.Imports...)
+}
+
+// nodes returns ast nodes that need to be rewritten like the package.
+func (c *contract) nodes() []ast.Node {
+	var nodes []ast.Node
+	if c.iface != nil {
+		nodes = append(nodes, c.iface)
+	}
+	if c.ptrIface != nil {
+		nodes = append(nodes, c.ptrIface)
+	}
+	if c.typeParamConstraint != nil {
+		nodes = append(nodes, c.typeParamConstraint)
+	}
+	for _, ta := range c.typeArgs {
+		nodes = append(nodes, ta.expr)
+	}
+	return nodes
+}
+
+const contractFilename = "gorewrite_contract.go"
+
+// contractFile declares contracts and their replacements as variables,
+// so that they are type-checked together with the rewritten package.
+func (s *Spec) contractFile(fset *token.FileSet, pkgName string, contracts []*contract) (*ast.File, error) {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "package %s\n", pkgName)
+
+	imports := make(map[string]struct{})
+	printImport := func(im string) {
+		if _, ok := imports[im]; ok {
+			return
+		}
+		imports[im] = struct{}{}
+		fmt.Fprintf(buf, "import %s\n", im)
+	}
+	for _, c := range contracts {
+		for _, spec := range c.imports {
+			im := spec.Path.Value
+			if spec.Name != nil {
+				im = spec.Name.Name + " " + im
+			}
+			printImport(im)
+		}
+		for _, im := range s.TypeMap[c.name].Import {
+			printImport(importSpec(im))
+		}
+	}
+
+	// Types that a type placeholder is converted from or to are declared in the same way.
+	conversions := make(map[*Constraint]string)
+	for _, c := range contracts {
+		for _, constraint := range c.constraints {
+			if constraint.typ == nil {
+				continue
+			}
+			expr, ims, ok := typeExpr(nil, constraint.typ)
+			if !ok {
+				continue
+			}
+			for _, im := range ims {
+				printImport(im)
+			}
+			conversions[constraint] = expr
+		}
+	}
+
+	for i, c := range contracts {
+		fmt.Fprintf(buf, "var gorewriteReplacement%d %s\n", i, s.TypeMap[c.name].Expr)
+		for j, iface := range []*ast.InterfaceType{c.iface, c.ptrIface} {
+			if iface == nil {
+				continue
+			}
+			fmt.Fprintf(buf, "var gorewriteContract%d_%d ", i, j)
+			err := printer.Fprint(buf, token.NewFileSet(), iface)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintln(buf)
+		}
+		if c.typeParamConstraint != nil {
+			// A constraint cannot be the type of a variable, so it is declared as an alias.
+			fmt.Fprintf(buf, "type gorewriteTypeParamConstraint%d = ", i)
+			err := printer.Fprint(buf, token.NewFileSet(), c.typeParamConstraint)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintln(buf)
+		}
+		for j, constraint := range c.constraints {
+			if expr, ok := conversions[constraint]; ok {
+				fmt.Fprintf(buf, "var gorewriteConversion%d_%d %s\n", i, j, expr)
+			}
+		}
+		for j, ta := range c.typeArgs {
+			fmt.Fprintf(buf, "var gorewriteTypeArg%d_%d ", i, j)
+			err := printer.Fprint(buf, token.NewFileSet(), ta.expr)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintln(buf)
+		}
+	}
+	return parser.ParseFile(fset, contractFilename, buf, parser.SkipObjectResolution)
+}
+
+// checkContract checks whether replacements satisfy contracts declared in contractFile.
+func (s *Spec) checkContract(pkg *types.Package, contracts []*contract) []error {
+	var errs []error
+	for i, c := range contracts {
+		repl, ok := pkg.Scope().Lookup(fmt.Sprintf("gorewriteReplacement%d", i)).(*types.Var)
+		if !ok || repl.Type() == types.Typ[types.Invalid] {
+			// The replacement itself is invalid, and it is reported as a type error.
+			continue
+		}
+		for j := 0; j < 2; j++ {
+			obj, ok := pkg.Scope().Lookup(fmt.Sprintf("gorewriteContract%d_%d", i, j)).(*types.Var)
+			if !ok {
+				continue
+			}
+			t := repl.Type()
+			if j == 1 {
+				t = types.NewPointer(t)
+			}
+			err := s.satisfy(pkg, c.name, t, obj.Type())
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if obj, ok := pkg.Scope().Lookup(fmt.Sprintf("gorewriteTypeParamConstraint%d", i)).(*types.TypeName); ok {
+			err := s.satisfy(pkg, c.name, repl.Type(), types.Unalias(obj.Type()))
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+		for j, ta := range c.typeArgs {
+			obj, ok := pkg.Scope().Lookup(fmt.Sprintf("gorewriteTypeArg%d_%d", i, j)).(*types.Var)
+			if !ok || obj.Type() == types.Typ[types.Invalid] || types.Identical(obj.Type(), repl.Type()) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("spec %s: %s is instantiated with %s, but %s is %s",
+				s.Name, ta.inst, ta.arg, c.name, s.TypeMap[c.name].Expr))
+		}
+		for j, constraint := range c.constraints {
+			var other types.Type
+			if obj, ok := pkg.Scope().Lookup(fmt.Sprintf("gorewriteConversion%d_%d", i, j)).(*types.Var); ok {
+				other = obj.Type()
+			}
+			if constraint.satisfiedBy(repl.Type(), other) {
+				continue
+			}
+			typeString := types.TypeString(repl.Type(), func(other *types.Package) string {
+				if other == pkg {
+					return ""
+				}
+				return other.Name()
+			})
+			errs = append(errs, fmt.Errorf("spec %s: %s does not satisfy placeholder %s: %s is not %s, but %s is %s at %s",
+				s.Name, typeString, c.name, typeString, constraint.Kind, c.name, constraint.Use, constraint.Pos))
+		}
+	}
+	return errs
+}
+
+// satisfy returns a readable error if t does not satisfy the contract of a type placeholder.
+func (s *Spec) satisfy(pkg *types.Package, name string, t, constraint types.Type) error {
+	iface, ok := constraint.Underlying().(*types.Interface)
+	if !ok || types.Satisfies(t, iface) {
+		return nil
+	}
+	qf := func(other *types.Package) string {
+		if other == pkg {
+			return ""
+		}
+		return other.Name()
+	}
+	typeString := types.TypeString(t, qf)
+	methodString := func(m *types.Func) string {
+		return m.Name() + strings.TrimPrefix(types.TypeString(m.Type(), qf), "func")
+	}
+
+	var reason string
+	if m, wrongType := types.MissingMethod(t, iface, true); m != nil {
+		if wrongType {
+			have, _, _ := types.LookupFieldOrMethod(t, true, m.Pkg(), m.Name())
+			if have, ok := have.(*types.Func); ok {
+				reason = fmt.Sprintf("wrong type for method %s: have %s, want %s", m.Name(), methodString(have), methodString(m))
+			} else {
+				reason = fmt.Sprintf("wrong type for method %s", m.Name())
+			}
+		} else {
+			reason = fmt.Sprintf("missing method %s", methodString(m))
+		}
+	} else if iface.IsComparable() && !types.Comparable(t) {
+		reason = fmt.Sprintf("%s is not comparable", typeString)
+	} else {
+		reason = fmt.Sprintf("%s is not in %s", typeString, types.TypeString(constraint, qf))
+	}
+	return fmt.Errorf("spec %s: %s does not satisfy placeholder %s: %s", s.Name, typeString, name, reason)
+}
@@ -0,0 +1,146 @@
+package rewrite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Change is a file that RewritePackage would write or remove.
+type Change struct {
+	// Spec is the name of the spec that the file belongs to.
+	Spec string
+	Path string
+
+	// Old is nil if the file does not exist on disk.
+	Old []byte
+	// New is nil if the file would be removed.
+	New []byte
+}
+
+// DryRun rewrites all specs like RewritePackage, but returns changes to files on disk instead of writing them.
+//
+// Files that would not change are not returned.
+func (c *Config) DryRun() ([]*Change, error) {
+	st, err := c.rewrite(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return st.changes()
+}
+
+// WriteDiff writes a unified diff from Old to New.
+func (ch *Change) WriteDiff(w io.Writer) error {
+	from, to := "a/"+filepath.ToSlash(ch.Path), "b/"+filepath.ToSlash(ch.Path)
+	if ch.Old == nil {
+		from = "/dev/null"
+	}
+	if ch.New == nil {
+		to = "/dev/null"
+	}
+	return difflib.WriteUnifiedDiff(w, difflib.UnifiedDiff{
+		A:        splitLines(ch.Old),
+		B:        splitLines(ch.New),
+		FromFile: from,
+		ToFile:   to,
+		Context:  3,
+	})
+}
+
+func splitLines(b []byte) []string {
+	if len(b) == 0 {
+		return nil
+	}
+	return difflib.SplitLines(string(b))
+}
+
+func (ch *Change) String() string {
+	switch {
+	case ch.Old == nil:
+		return fmt.Sprintf("%s: add %s", ch.Spec, ch.Path)
+	case ch.New == nil:
+		return fmt.Sprintf("%s: remove %s", ch.Spec, ch.Path)
+	default:
+		return fmt.Sprintf("%s: update %s", ch.Spec, ch.Path)
+	}
+}
+
+// changes compares staged files with files on disk.
+func (st *stage) changes() ([]*Change, error) {
+	var changes []*Change
+	for _, ss := range st.specs {
+		staged := make(map[string]struct{})
+		for _, path := range ss.files {
+			staged[path] = struct{}{}
+
+			old, err := os.ReadFile(st.path(path))
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			if bytes.Equal(old, st.files[path]) && err == nil {
+				continue
+			}
+			changes = append(changes, &Change{
+				Spec: ss.name,
+				Path: path,
+				Old:  old,
+				New:  st.files[path],
+			})
+		}
+		for _, path := range ss.remove {
+			old, err := os.ReadFile(st.path(path))
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, &Change{
+				Spec: ss.name,
+				Path: path,
+				Old:  old,
+			})
+		}
+		if ss.dir == "" {
+			continue
+		}
+
+		// Everything else in a replaced directory is removed.
+		dir := st.path(ss.dir)
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == dir {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(st.dir, path)
+			if err != nil {
+				return err
+			}
+			if _, ok := staged[rel]; ok {
+				return nil
+			}
+			old, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, &Change{
+				Spec: ss.name,
+				Path: rel,
+				Old:  old,
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
@@ -6,6 +6,8 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/pmezard/go-difflib/difflib"
@@ -16,34 +18,104 @@ func testRewritePackage(t *testing.T, c *Config, expect string) {
 }
 
 func testRewritePackageWithInput(t *testing.T, c *Config, input, expect string) {
+	testOutput(t, input, inDir(c, (*Config).RewritePackage), expect)
+}
+
+// inDir returns a function that runs f with c in a directory.
+func inDir(c *Config, f func(*Config) error) func(string) error {
+	return func(dir string) error {
+		c.Dir = dir
+		c.PackageName = "GOPACKAGE"
+		return f(c)
+	}
+}
+
+// tempDir creates an empty directory for a test, and returns its absolute path.
+func tempDir(t *testing.T) (string, func()) {
 	const dirname = "tmp"
 	err := os.MkdirAll(dirname, 0777)
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer os.RemoveAll(dirname)
+	dir, err := filepath.Abs(dirname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// testOutput runs f in a copy of input, and compares the result with expect.
+func testOutput(t *testing.T, input string, f func(string) error, expect string) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
 
 	if input != "" {
-		err = copyDir(dirname, input)
+		err := copyDir(dir, input)
 		if err != nil {
 			t.Fatal(err)
 		}
 	}
 
-	os.Setenv("GOPACKAGE", "GOPACKAGE")
+	err := f(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqualDir(t, expect, dir)
+}
+
+func testRewritePackageError(t *testing.T, c *Config, input string, expect ...string) {
+	testError(t, input, inDir(c, (*Config).RewritePackage), expect...)
+}
 
-	err = os.Chdir(dirname)
+// testError runs f in a copy of input, and checks that it fails.
+func testError(t *testing.T, input string, f func(string) error, expect ...string) {
+	err := runInDir(t, input, f)
+	if err == nil {
+		t.Fatal("expect error")
+	}
+	for _, text := range expect {
+		if !strings.Contains(err.Error(), text) {
+			t.Fatalf("expect %q in error:\n%s", text, err)
+		}
+	}
+}
+
+// runInDir runs f in a copy of input, and checks that f does not change anything.
+func runInDir(t *testing.T, input string, f func(string) error) error {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	err := copyDir(dir, input)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = c.RewritePackage()
-	os.Chdir("..")
+	err = f(dir)
+
+	assertEqualDir(t, input, dir)
+	return err
+}
+
+func testDryRun(t *testing.T, c *Config, input string, expect ...string) []*Change {
+	var changes []*Change
+	err := runInDir(t, input, inDir(c, func(c *Config) error {
+		var err error
+		changes, err = c.DryRun()
+		return err
+	}))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	assertEqualDir(t, expect, dirname)
+	var got []string
+	for _, ch := range changes {
+		got = append(got, ch.String())
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expect %q, got %q", expect, got)
+	}
+	return changes
 }
 
 func copyDir(to, from string) error {
@@ -53,6 +125,14 @@ func copyDir(to, from string) error {
 	}
 	for _, info := range fi {
 		if info.IsDir() {
+			err := os.Mkdir(filepath.Join(to, info.Name()), 0777)
+			if err != nil {
+				return err
+			}
+			err = copyDir(filepath.Join(to, info.Name()), filepath.Join(from, info.Name()))
+			if err != nil {
+				return err
+			}
 			continue
 		}
 
@@ -0,0 +1,147 @@
+package rewrite
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// splitImport splits an import of typeMap into its name and path.
+//
+// An import is either a path, or a name and a path separated by a space like `geo2 github.com/b/geo`.
+// The name is empty if it is not given.
+func splitImport(im string) (name, path string) {
+	fields := strings.Fields(im)
+	if len(fields) == 2 {
+		return fields[0], fields[1]
+	}
+	return "", strings.TrimSpace(im)
+}
+
+// importSpec returns an import of typeMap as it is written in an import declaration.
+func importSpec(im string) string {
+	name, path := splitImport(im)
+	if name == "" {
+		return strconv.Quote(path)
+	}
+	return name + " " + strconv.Quote(path)
+}
+
+// addImport adds an import of typeMap to node.
+func addImport(fset *token.FileSet, node *ast.File, im string) {
+	name, path := splitImport(im)
+	astutil.AddNamedImport(fset, node, name, path)
+}
+
+// defaultImportName guesses the package name of an import path without loading it,
+// like goimports does: a major version suffix, and a `go-` prefix or a `-go` suffix are ignored.
+func defaultImportName(importPath string) string {
+	base := path.Base(importPath)
+	if strings.HasPrefix(base, "v") {
+		if _, err := strconv.Atoi(base[1:]); err == nil {
+			if dir := path.Dir(importPath); dir != "." {
+				base = path.Base(dir)
+			}
+		}
+	}
+	base = strings.TrimPrefix(base, "go-")
+	base = strings.TrimSuffix(base, "-go")
+	if i := strings.IndexAny(base, ".-"); i >= 0 {
+		base = base[:i]
+	}
+	return base
+}
+
+// resolveImportConflicts returns a copy of the spec whose imports in typeMap do not conflict with the template,
+// or with each other.
+//
+// An import conflicts if its name is taken by another package that the template imports,
+// or by an identifier that the template declares. It is given a unique alias instead,
+// and its qualifier in expr is renamed to match.
+func (s *Spec) resolveImportConflicts(pkg *Package, importer types.Importer) (*Spec, error) {
+	// taken maps a name to the import path that takes it, or an empty string for an identifier.
+	taken := make(map[string]string)
+	for ident, obj := range pkg.info.Defs {
+		if obj == nil {
+			continue
+		}
+		if pkgName, ok := obj.(*types.PkgName); ok {
+			taken[ident.Name] = pkgName.Imported().Path()
+			continue
+		}
+		taken[ident.Name] = ""
+	}
+	for _, obj := range pkg.info.Implicits {
+		if pkgName, ok := obj.(*types.PkgName); ok {
+			taken[pkgName.Name()] = pkgName.Imported().Path()
+		}
+	}
+
+	typeMap := make(map[string]Type)
+	for _, key := range sortedKeys(s.TypeMap) {
+		to := s.TypeMap[key]
+		rename := make(map[string]string)
+		imports := make([]string, len(to.Import))
+		for i, im := range to.Import {
+			imports[i] = im
+			name, path := splitImport(im)
+			if name == "" {
+				name = defaultImportName(path)
+				if p, err := importer.Import(path); err == nil {
+					name = p.Name()
+				}
+			}
+			if other, ok := taken[name]; !ok || other == path {
+				taken[name] = path
+				continue
+			}
+			alias := name
+			for i := 2; ; i++ {
+				alias = name + strconv.Itoa(i)
+				if _, ok := taken[alias]; !ok {
+					break
+				}
+			}
+			taken[alias] = path
+			rename[name] = alias
+			imports[i] = alias + " " + path
+		}
+		to.Import = imports
+		if len(rename) > 0 {
+			expr, err := parser.ParseExpr(to.Expr)
+			if err != nil {
+				return nil, fmt.Errorf("spec %s: typeMap %s: %w", s.Name, key, err)
+			}
+			ast.Inspect(expr, func(n ast.Node) bool {
+				if x, ok := n.(*ast.SelectorExpr); ok {
+					if qual, ok := x.X.(*ast.Ident); ok {
+						if alias, ok := rename[qual.Name]; ok {
+							qual.Name = alias
+						}
+					}
+				}
+				return true
+			})
+			buf := new(bytes.Buffer)
+			err = printer.Fprint(buf, token.NewFileSet(), expr)
+			if err != nil {
+				return nil, err
+			}
+			to.Expr = buf.String()
+		}
+		typeMap[key] = to
+	}
+
+	spec := *s
+	spec.TypeMap = typeMap
+	return &spec, nil
+}
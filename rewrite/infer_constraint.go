@@ -0,0 +1,298 @@
+package rewrite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Constraint is implied by how a type placeholder is used in the package that it is rewritten from.
+type Constraint struct {
+	// Placeholder is the name of the type placeholder.
+	Placeholder string
+	// Kind is what its replacement must be, like "ordered" or "comparable".
+	Kind string
+	// Use is how the type placeholder is used, like "used with <" or "used as a map key".
+	Use string
+	// Pos is where it is first used.
+	Pos token.Position
+
+	// typ is the other type of a conversion.
+	typ types.Type
+}
+
+func (c *Constraint) String() string {
+	return fmt.Sprintf("%s must be %s: %s at %s", c.Placeholder, c.Kind, c.Use, c.Pos)
+}
+
+const (
+	constraintOrdered         = "ordered"
+	constraintComparable      = "comparable"
+	constraintNumericOrString = "numeric or string"
+	constraintNumeric         = "numeric"
+	constraintInteger         = "integer"
+	constraintRangeable       = "rangeable"
+)
+
+var operatorConstraint = map[token.Token]string{
+	token.LSS:     constraintOrdered,
+	token.GTR:     constraintOrdered,
+	token.LEQ:     constraintOrdered,
+	token.GEQ:     constraintOrdered,
+	token.EQL:     constraintComparable,
+	token.NEQ:     constraintComparable,
+	token.ADD:     constraintNumericOrString,
+	token.SUB:     constraintNumeric,
+	token.MUL:     constraintNumeric,
+	token.QUO:     constraintNumeric,
+	token.INC:     constraintNumeric,
+	token.DEC:     constraintNumeric,
+	token.REM:     constraintInteger,
+	token.AND:     constraintInteger,
+	token.OR:      constraintInteger,
+	token.XOR:     constraintInteger,
+	token.AND_NOT: constraintInteger,
+	token.SHL:     constraintInteger,
+	token.SHR:     constraintInteger,
+}
+
+var assignOperator = map[token.Token]token.Token{
+	token.ADD_ASSIGN:     token.ADD,
+	token.SUB_ASSIGN:     token.SUB,
+	token.MUL_ASSIGN:     token.MUL,
+	token.QUO_ASSIGN:     token.QUO,
+	token.REM_ASSIGN:     token.REM,
+	token.AND_ASSIGN:     token.AND,
+	token.OR_ASSIGN:      token.OR,
+	token.XOR_ASSIGN:     token.XOR,
+	token.SHL_ASSIGN:     token.SHL,
+	token.SHR_ASSIGN:     token.SHR,
+	token.AND_NOT_ASSIGN: token.AND_NOT,
+}
+
+// InferConstraints finds constraints of type placeholders from how they are used in a package that is imported from dir.
+//
+// Type placeholders are types whose names start with Type, and are declared with an identifier or an interface.
+func InferConstraints(dir, importPath string) ([]*Constraint, error) {
+	s := &Spec{Import: importPath}
+	pkg, err := s.parse(context.Background(), dir)
+	if err != nil {
+		return nil, err
+	}
+	if pkg.types == nil {
+		return nil, errors.New("type information is not found")
+	}
+	names := make(map[string]struct{})
+	for _, name := range pkg.types.Scope().Names() {
+		if !strings.HasPrefix(name, "Type") {
+			continue
+		}
+		for _, node := range pkg.Files {
+			for _, decl := range node.Decls {
+				decl, ok := decl.(*ast.GenDecl)
+				if !ok {
+					continue
+				}
+				for _, spec := range decl.Specs {
+					spec, ok := spec.(*ast.TypeSpec)
+					if ok && spec.Name.Name == name && isPlaceholder(spec) {
+						names[name] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+	return inferConstraints(pkg, names), nil
+}
+
+// isPlaceholder returns true if a type declaration can be removed and replaced.
+func isPlaceholder(spec *ast.TypeSpec) bool {
+	switch spec.Type.(type) {
+	case *ast.Ident, *ast.InterfaceType:
+		return true
+	}
+	return false
+}
+
+// inferConstraints finds constraints of type placeholders in names.
+func inferConstraints(pkg *Package, names map[string]struct{}) []*Constraint {
+	if pkg.types == nil || pkg.info == nil {
+		return nil
+	}
+	placeholder := make(map[types.Type]string)
+	for name := range names {
+		obj, ok := pkg.types.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		placeholder[obj.Type()] = name
+	}
+
+	var constraints []*Constraint
+	found := make(map[string]struct{})
+	add := func(expr ast.Expr, kind, use string, typ types.Type) {
+		name, ok := placeholder[pkg.info.TypeOf(expr)]
+		if !ok {
+			return
+		}
+		key := name + " " + kind
+		if _, ok := found[key]; ok {
+			return
+		}
+		found[key] = struct{}{}
+		pos := pkg.FileSet.Position(expr.Pos())
+		pos.Filename = filepath.Base(pos.Filename)
+		constraints = append(constraints, &Constraint{
+			Placeholder: name,
+			Kind:        kind,
+			Use:         use,
+			Pos:         pos,
+			typ:         typ,
+		})
+	}
+	addOperator := func(expr ast.Expr, op token.Token) {
+		kind, ok := operatorConstraint[op]
+		if !ok {
+			return
+		}
+		add(expr, kind, fmt.Sprintf("used with %s", op), nil)
+	}
+
+	var paths []string
+	for path := range pkg.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		ast.Inspect(pkg.Files[path], func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.BinaryExpr:
+				addOperator(x.X, x.Op)
+				addOperator(x.Y, x.Op)
+			case *ast.UnaryExpr:
+				switch x.Op {
+				case token.SUB, token.ADD:
+					add(x.X, constraintNumeric, fmt.Sprintf("used with unary %s", x.Op), nil)
+				case token.XOR:
+					add(x.X, constraintInteger, fmt.Sprintf("used with unary %s", x.Op), nil)
+				}
+			case *ast.IncDecStmt:
+				addOperator(x.X, x.Tok)
+			case *ast.AssignStmt:
+				if op, ok := assignOperator[x.Tok]; ok {
+					addOperator(x.Lhs[0], op)
+				}
+			case *ast.MapType:
+				add(x.Key, constraintComparable, "used as a map key", nil)
+			case *ast.SwitchStmt:
+				if x.Tag != nil {
+					add(x.Tag, constraintComparable, "used in a switch", nil)
+				}
+			case *ast.RangeStmt:
+				add(x.X, constraintRangeable, "ranged over", nil)
+			case *ast.CallExpr:
+				if len(x.Args) != 1 {
+					return true
+				}
+				tv, ok := pkg.info.Types[x.Fun]
+				if !ok || !tv.IsType() {
+					return true
+				}
+				from, to := pkg.info.TypeOf(x.Args[0]), tv.Type
+				if from == nil {
+					return true
+				}
+				from = types.Default(from)
+				_, fromPlaceholder := placeholder[from]
+				_, toPlaceholder := placeholder[to]
+				if fromPlaceholder == toPlaceholder {
+					return true
+				}
+				if toPlaceholder {
+					if _, _, ok := typeExpr(pkg.types, from); ok {
+						add(x.Fun, fmt.Sprintf("convertible from %s", from), fmt.Sprintf("converted from %s", from), from)
+					}
+				} else {
+					if _, _, ok := typeExpr(pkg.types, to); ok {
+						add(x.Args[0], fmt.Sprintf("convertible to %s", to), fmt.Sprintf("converted to %s", to), to)
+					}
+				}
+			}
+			return true
+		})
+	}
+	return constraints
+}
+
+// typeExpr returns how a type is written outside of the package, and imports that it needs.
+//
+// Only basic types, types from other packages, and slices of them are supported.
+func typeExpr(pkg *types.Package, t types.Type) (string, []string, bool) {
+	switch t := t.(type) {
+	case *types.Basic:
+		if t.Info()&types.IsUntyped != 0 {
+			return "", nil, false
+		}
+		return t.Name(), nil, true
+	case *types.Named:
+		obj := t.Obj()
+		if obj.Pkg() == nil {
+			// error
+			return obj.Name(), nil, true
+		}
+		if obj.Pkg() == pkg || t.TypeArgs().Len() > 0 {
+			return "", nil, false
+		}
+		return obj.Pkg().Name() + "." + obj.Name(), []string{fmt.Sprintf("%s %q", obj.Pkg().Name(), obj.Pkg().Path())}, true
+	case *types.Slice:
+		expr, imports, ok := typeExpr(pkg, t.Elem())
+		return "[]" + expr, imports, ok
+	}
+	return "", nil, false
+}
+
+// satisfiedBy returns true if t satisfies the constraint.
+//
+// other is the other type of a conversion.
+func (c *Constraint) satisfiedBy(t, other types.Type) bool {
+	var info types.BasicInfo
+	if b, ok := t.Underlying().(*types.Basic); ok {
+		info = b.Info()
+	}
+	switch c.Kind {
+	case constraintOrdered:
+		return info&types.IsOrdered != 0
+	case constraintComparable:
+		return types.Comparable(t)
+	case constraintNumericOrString:
+		return info&(types.IsNumeric|types.IsString) != 0
+	case constraintNumeric:
+		return info&types.IsNumeric != 0
+	case constraintInteger:
+		return info&types.IsInteger != 0
+	case constraintRangeable:
+		switch u := t.Underlying().(type) {
+		case *types.Basic:
+			return info&(types.IsString|types.IsInteger) != 0
+		case *types.Array, *types.Slice, *types.Map, *types.Chan, *types.Signature:
+			return true
+		case *types.Pointer:
+			_, ok := u.Elem().Underlying().(*types.Array)
+			return ok
+		}
+		return false
+	}
+	if other == nil {
+		return true
+	}
+	if strings.HasPrefix(c.Kind, "convertible from ") {
+		return types.ConvertibleTo(other, t)
+	}
+	return types.ConvertibleTo(t, other)
+}
@@ -0,0 +1,188 @@
+package rewrite
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// inferImports returns a copy of the spec with imports of package qualifiers in typeMap that are not imported.
+//
+// Imports are found like goimports does, from GOROOT, the current module and its dependencies.
+// A package is only used if it declares every name that is qualified by it.
+// Packages listed in import are always used for their names, so they decide when a name is ambiguous.
+func (s *Spec) inferImports(ctx context.Context, importer types.Importer, resolver *importResolver) (*Spec, error) {
+	typeMap := make(map[string]Type)
+	for _, key := range sortedKeys(s.TypeMap) {
+		to := s.TypeMap[key]
+		typeMap[key] = to
+
+		imported := make(map[string]struct{})
+		for _, im := range to.Import {
+			name, path := splitImport(im)
+			pkg, err := importer.Import(path)
+			if err != nil {
+				// It is reported by checkTypeImports.
+				continue
+			}
+			if name == "" {
+				name = pkg.Name()
+			}
+			imported[name] = struct{}{}
+		}
+		missing := make(map[string][]string)
+		expr, _ := parser.ParseExpr(to.Expr)
+		ast.Inspect(expr, func(n ast.Node) bool {
+			if x, ok := n.(*ast.SelectorExpr); ok {
+				if qual, ok := x.X.(*ast.Ident); ok {
+					if _, ok := imported[qual.Name]; !ok {
+						missing[qual.Name] = append(missing[qual.Name], x.Sel.Name)
+					}
+				}
+			}
+			return true
+		})
+		if len(missing) == 0 {
+			continue
+		}
+
+		to.Import = append([]string(nil), to.Import...)
+		for _, qual := range sortedKeys(missing) {
+			paths, err := resolver.candidates(ctx, qual)
+			if err != nil {
+				return nil, fmt.Errorf("spec %s: typeMap %s: %w", s.Name, key, err)
+			}
+			for _, path := range paths {
+				pkg, err := importer.Import(path)
+				if err != nil || !declares(pkg, missing[qual]) {
+					continue
+				}
+				// If none is found, it is reported by checkTypeImports.
+				to.Import = append(to.Import, path)
+				break
+			}
+		}
+		typeMap[key] = to
+	}
+
+	spec := *s
+	spec.TypeMap = typeMap
+	return &spec, nil
+}
+
+// declares returns true if pkg declares every name as an exported type.
+func declares(pkg *types.Package, names []string) bool {
+	for _, name := range names {
+		obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok || !obj.Exported() {
+			return false
+		}
+	}
+	return true
+}
+
+// importResolver finds import paths of package names without network access.
+//
+// Packages are listed once from GOROOT, the current module and its dependencies,
+// so that specs of a config share them.
+type importResolver struct {
+	dir   string
+	once  sync.Once
+	paths map[string][]string
+	err   error
+}
+
+func newImportResolver(dir string) *importResolver {
+	return &importResolver{dir: dir}
+}
+
+// candidates returns import paths of packages that are named name, in the order that goimports prefers them.
+//
+// Packages in GOROOT come first, then those with higher major versions and shorter paths.
+// Internal and vendored packages are not candidates.
+func (r *importResolver) candidates(ctx context.Context, name string) ([]string, error) {
+	r.once.Do(func() {
+		pkgs, err := packages.Load(&packages.Config{
+			Context: ctx,
+			Mode:    packages.NeedName,
+			Dir:     r.dir,
+			Env:     append(os.Environ(), "GOPROXY=off"),
+		}, "std", "all")
+		if err != nil {
+			r.err = err
+			return
+		}
+		r.paths = make(map[string][]string)
+		seen := make(map[string]struct{})
+		for _, pkg := range pkgs {
+			if pkg.Name == "" || pkg.Name == "main" || !importable(pkg.PkgPath) {
+				continue
+			}
+			// Packages in GOROOT are listed by both patterns.
+			if _, ok := seen[pkg.PkgPath]; ok {
+				continue
+			}
+			seen[pkg.PkgPath] = struct{}{}
+			r.paths[pkg.Name] = append(r.paths[pkg.Name], pkg.PkgPath)
+		}
+		for _, paths := range r.paths {
+			slices.SortFunc(paths, compareImportPath)
+		}
+	})
+	return r.paths[name], r.err
+}
+
+// importable returns true if a package can be imported from anywhere.
+func importable(path string) bool {
+	for _, elem := range strings.Split(path, "/") {
+		if elem == "internal" || elem == "vendor" {
+			return false
+		}
+	}
+	return true
+}
+
+// compareImportPath orders import paths like goimports prefers them.
+func compareImportPath(a, b string) int {
+	if isStd(a) != isStd(b) {
+		if isStd(a) {
+			return -1
+		}
+		return 1
+	}
+	if va, vb := majorVersion(a), majorVersion(b); va != vb {
+		return vb - va
+	}
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
+
+// isStd returns true if path is a package in GOROOT, whose first element has no dot.
+func isStd(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// majorVersion returns the major version of a package from its /vN suffix.
+func majorVersion(path string) int {
+	i := strings.LastIndex(path, "/v")
+	if i < 0 {
+		return 1
+	}
+	v, err := strconv.Atoi(path[i+2:])
+	if err != nil || v < 2 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,123 @@
+package rewrite
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+
+	"golang.org/x/tools/go/gcexportdata"
+	"golang.org/x/tools/go/packages"
+)
+
+// loadPackage finds a package like the go command does in dir.
+//
+// It understands GOPATH, vendor/, go.mod, go.sum, replace directives and go.work.
+// Modules are only read from the local module cache, so it never downloads anything.
+//
+// Files in overlay are used in place of files on disk.
+// Type errors are not returned because type information is still useful.
+func loadPackage(ctx context.Context, fset *token.FileSet, dir, path string, mode packages.LoadMode, overlay map[string][]byte) (*packages.Package, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Context: ctx,
+		Mode:    mode,
+		Dir:     dir,
+		Fset:    fset,
+		Env:     append(os.Environ(), "GOPROXY=off"),
+		Overlay: overlay,
+		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+			return parser.ParseFile(fset, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+		},
+	}, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%s: found %d packages", path, len(pkgs))
+	}
+	p := pkgs[0]
+	for _, err := range p.Errors {
+		if err.Kind != packages.TypeError {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// packageImporter imports type information of dependencies with loadPackage.
+//
+// Export data of every import is read into the same packages,
+// so a type that two imports refer to, like time.Duration, is the same type.
+type packageImporter struct {
+	ctx     context.Context
+	fset    *token.FileSet
+	dir     string
+	overlay map[string][]byte
+	// imported maps import paths to packages, and pkgs maps package paths to packages,
+	// which differ if a package is vendored.
+	imported map[string]*types.Package
+	pkgs     map[string]*types.Package
+}
+
+func newImporter(ctx context.Context, fset *token.FileSet, dir string, overlay map[string][]byte) types.Importer {
+	return &packageImporter{
+		ctx:      ctx,
+		fset:     fset,
+		dir:      dir,
+		overlay:  overlay,
+		imported: make(map[string]*types.Package),
+		pkgs:     make(map[string]*types.Package),
+	}
+}
+
+func (im *packageImporter) Import(path string) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if p, ok := im.imported[path]; ok {
+		return p, nil
+	}
+	p, err := loadPackage(im.ctx, im.fset, im.dir, path, packages.NeedName|packages.NeedExportFile, im.overlay)
+	if err != nil {
+		return nil, err
+	}
+	if typesP, ok := im.pkgs[p.PkgPath]; ok && typesP.Complete() {
+		im.imported[path] = typesP
+		return typesP, nil
+	}
+	if p.ExportFile == "" {
+		return nil, fmt.Errorf("%s: export data is not found", path)
+	}
+	f, err := os.Open(p.ExportFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := gcexportdata.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	typesP, err := gcexportdata.Read(r, im.fset, im.pkgs, p.PkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	im.imported[path] = typesP
+	return typesP, nil
+}
+
+// newTypesInfo returns types.Info that records everything packages.NeedTypesInfo does.
+func newTypesInfo() *types.Info {
+	return &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Instances:  make(map[*ast.Ident]types.Instance),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+	}
+}
@@ -0,0 +1,555 @@
+package rewrite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// Migrate converts type placeholders in a package that is imported from dir to type parameters,
+// and writes the generic package to outputDir, which is relative to dir.
+//
+// Every type and function that uses type placeholders, directly or through other declarations, gets them as
+// type parameters, and its uses are instantiated. Methods on type placeholders, and constraints inferred from
+// how they are used become constraints of type parameters.
+// outputDir is replaced as a whole after the generic package is type-checked, so it cannot be dir itself.
+func Migrate(dir, importPath, outputDir string) error {
+	err := checkOutputDir(outputDir, dir)
+	if err != nil {
+		return fmt.Errorf("migrate %s: %w", importPath, err)
+	}
+	pkg, err := (&Spec{Import: importPath}).parse(context.Background(), dir)
+	if err != nil {
+		return err
+	}
+	if pkg.types == nil {
+		return fmt.Errorf("migrate %s: type information is not found", importPath)
+	}
+	err = migrate(importPath, pkg)
+	if err != nil {
+		return err
+	}
+	err = pkg.Reset()
+	if err != nil {
+		return err
+	}
+	err = checkMigrated(dir, importPath, pkg)
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string][]byte)
+	for path, f := range pkg.Files {
+		buf := new(bytes.Buffer)
+		err := format.Node(buf, pkg.FileSet, f)
+		if err != nil {
+			return err
+		}
+		files[filepath.Join(outputDir, filepath.Base(path))] = buf.Bytes()
+	}
+	st := newStage(dir)
+	err = st.add(outputDir, outputDir, files, nil)
+	if err != nil {
+		return err
+	}
+	return (&DiskSink{Dir: dir}).Write(context.Background(), st.output())
+}
+
+// migratedPlaceholder is a type placeholder that becomes a type parameter.
+type migratedPlaceholder struct {
+	name string
+	spec *ast.TypeSpec
+	// methods are declared on the type placeholder.
+	methods []*ast.FuncDecl
+	// constraint is printed in type parameter lists.
+	constraint string
+	// imports are what the constraint needs.
+	imports map[*types.PkgName]struct{}
+}
+
+func migrate(importPath string, pkg *Package) error {
+	var paths []string
+	for path := range pkg.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// Find type placeholders.
+	placeholders := make(map[types.Object]*migratedPlaceholder)
+	names := make(map[string]struct{})
+	for _, path := range paths {
+		for _, decl := range pkg.Files[path].Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				spec, ok := spec.(*ast.TypeSpec)
+				if !ok || !strings.HasPrefix(spec.Name.Name, "Type") || !isPlaceholder(spec) {
+					continue
+				}
+				placeholders[pkg.info.Defs[spec.Name]] = &migratedPlaceholder{
+					name:    spec.Name.Name,
+					spec:    spec,
+					imports: make(map[*types.PkgName]struct{}),
+				}
+				names[spec.Name.Name] = struct{}{}
+			}
+		}
+	}
+	if len(placeholders) == 0 {
+		return fmt.Errorf("migrate %s: type placeholder is not found", importPath)
+	}
+	recvObj := func(decl *ast.FuncDecl) types.Object {
+		recv := decl.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+		ident, ok := recv.(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		return pkg.info.Uses[ident]
+	}
+	for _, path := range paths {
+		for _, decl := range pkg.Files[path].Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Recv == nil {
+				continue
+			}
+			p, ok := placeholders[recvObj(decl)]
+			if !ok {
+				continue
+			}
+			if _, ok := decl.Recv.List[0].Type.(*ast.StarExpr); ok {
+				return fmt.Errorf("migrate %s: method %s of type placeholder %s has a pointer receiver", importPath, decl.Name.Name, p.name)
+			}
+			p.methods = append(p.methods, decl)
+		}
+	}
+
+	// Constraints are printed before the package is changed.
+	constraints := make(map[string][]*Constraint)
+	for _, c := range inferConstraints(pkg, names) {
+		constraints[c.Placeholder] = append(constraints[c.Placeholder], c)
+	}
+	for _, p := range placeholders {
+		err := p.buildConstraint(pkg, constraints[p.name])
+		if err != nil {
+			return fmt.Errorf("migrate %s: %v", importPath, err)
+		}
+	}
+
+	// Find which type placeholders each declaration uses, and which declarations it depends on.
+	// Methods are part of their receiver type.
+	uses := make(map[types.Object]map[types.Object]struct{})
+	deps := make(map[types.Object]map[types.Object]struct{})
+	var values []*ast.ValueSpec
+	var decls []types.Object
+	visit := func(obj types.Object, node ast.Node) {
+		if uses[obj] == nil {
+			uses[obj] = make(map[types.Object]struct{})
+			deps[obj] = make(map[types.Object]struct{})
+			decls = append(decls, obj)
+		}
+		ast.Inspect(node, func(n ast.Node) bool {
+			if x, ok := n.(*ast.Ident); ok {
+				used := pkg.info.Uses[x]
+				if _, ok := placeholders[used]; ok {
+					uses[obj][used] = struct{}{}
+				} else if used != nil && used.Parent() == pkg.types.Scope() && used != obj {
+					deps[obj][used] = struct{}{}
+				}
+			}
+			return true
+		})
+	}
+	for _, path := range paths {
+		for _, decl := range pkg.Files[path].Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					visit(pkg.info.Defs[decl.Name], decl)
+					continue
+				}
+				obj := recvObj(decl)
+				if _, ok := placeholders[obj]; ok || obj == nil {
+					continue
+				}
+				visit(obj, decl)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						obj := pkg.info.Defs[spec.Name]
+						if _, ok := placeholders[obj]; ok {
+							continue
+						}
+						visit(obj, spec)
+					case *ast.ValueSpec:
+						values = append(values, spec)
+					}
+				}
+			}
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, obj := range decls {
+			for dep := range deps[obj] {
+				for used := range uses[dep] {
+					if _, ok := uses[obj][used]; !ok {
+						uses[obj][used] = struct{}{}
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	typeParams := make(map[types.Object][]*migratedPlaceholder)
+	for _, obj := range decls {
+		for used := range uses[obj] {
+			typeParams[obj] = append(typeParams[obj], placeholders[used])
+		}
+		sort.Slice(typeParams[obj], func(i, j int) bool {
+			return typeParams[obj][i].name < typeParams[obj][j].name
+		})
+	}
+	for _, spec := range values {
+		for _, name := range spec.Names {
+			var found bool
+			ast.Inspect(spec, func(n ast.Node) bool {
+				if x, ok := n.(*ast.Ident); ok {
+					used := pkg.info.Uses[x]
+					if _, ok := placeholders[used]; ok || len(typeParams[used]) > 0 {
+						found = true
+					}
+				}
+				return !found
+			})
+			if found {
+				return fmt.Errorf("migrate %s: %s uses type placeholders, but it cannot have type parameters", importPath, name.Name)
+			}
+		}
+	}
+
+	typeParamList := func(params []*migratedPlaceholder) *ast.FieldList {
+		list := &ast.FieldList{}
+		for _, p := range params {
+			list.List = append(list.List, &ast.Field{
+				Names: []*ast.Ident{ast.NewIdent(p.name)},
+				Type:  ast.NewIdent(p.constraint),
+			})
+		}
+		return list
+	}
+	instantiate := func(x ast.Expr, params []*migratedPlaceholder) ast.Expr {
+		// New nodes are positioned at the end of x, so that they are printed on the same line.
+		pos := x.End()
+		var indices []ast.Expr
+		for _, p := range params {
+			indices = append(indices, &ast.Ident{NamePos: pos, Name: p.name})
+		}
+		if len(indices) == 1 {
+			return &ast.IndexExpr{X: x, Lbrack: pos, Index: indices[0], Rbrack: pos}
+		}
+		return &ast.IndexListExpr{X: x, Lbrack: pos, Indices: indices, Rbrack: pos}
+	}
+
+	for _, path := range paths {
+		node := pkg.Files[path]
+
+		// Remove type placeholders and their methods.
+		removed := make(map[*types.PkgName]struct{})
+		for i := len(node.Decls) - 1; i >= 0; i-- {
+			var remove bool
+			switch decl := node.Decls[i].(type) {
+			case *ast.FuncDecl:
+				if decl.Recv != nil {
+					_, remove = placeholders[recvObj(decl)]
+				}
+			case *ast.GenDecl:
+				var specs []ast.Spec
+				for _, spec := range decl.Specs {
+					if spec, ok := spec.(*ast.TypeSpec); ok {
+						if _, ok := placeholders[pkg.info.Defs[spec.Name]]; ok {
+							usedImports(pkg.info, spec, removed)
+							continue
+						}
+					}
+					specs = append(specs, spec)
+				}
+				remove = len(specs) == 0
+				if !remove {
+					decl.Specs = specs
+				}
+			}
+			if remove {
+				usedImports(pkg.info, node.Decls[i], removed)
+				removeComments(pkg.FileSet, node, node.Decls[i])
+				node.Decls = append(node.Decls[:i], node.Decls[i+1:]...)
+			}
+		}
+
+		// Add type parameters, and instantiate uses.
+		recvs := make(map[*ast.Ident]struct{})
+		imports := make(map[*types.PkgName]struct{})
+		addImports := func(params []*migratedPlaceholder) {
+			for _, p := range params {
+				for pkgName := range p.imports {
+					imports[pkgName] = struct{}{}
+				}
+			}
+		}
+		for _, decl := range node.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					params := typeParams[pkg.info.Defs[decl.Name]]
+					if len(params) > 0 {
+						decl.Type.TypeParams = typeParamList(params)
+						addImports(params)
+					}
+					continue
+				}
+				params := typeParams[recvObj(decl)]
+				if len(params) == 0 {
+					continue
+				}
+				field := decl.Recv.List[0]
+				recv := &field.Type
+				if star, ok := field.Type.(*ast.StarExpr); ok {
+					recv = &star.X
+				}
+				if ident, ok := (*recv).(*ast.Ident); ok {
+					recvs[ident] = struct{}{}
+				}
+				*recv = instantiate(*recv, params)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					spec, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					params := typeParams[pkg.info.Defs[spec.Name]]
+					if len(params) > 0 {
+						spec.TypeParams = typeParamList(params)
+						addImports(params)
+					}
+				}
+			}
+		}
+		astutil.Apply(node, nil, func(c *astutil.Cursor) bool {
+			x, ok := c.Node().(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if _, ok := recvs[x]; ok {
+				return true
+			}
+			params := typeParams[pkg.info.Uses[x]]
+			if len(params) > 0 {
+				c.Replace(instantiate(x, params))
+			}
+			return true
+		})
+
+		deleteUnusedImports(pkg.FileSet, pkg.info, node, removed)
+		for pkgName := range imports {
+			name := pkgName.Name()
+			if name == pkgName.Imported().Name() {
+				name = ""
+			}
+			astutil.AddNamedImport(pkg.FileSet, node, name, pkgName.Imported().Path())
+		}
+		if len(node.Decls) == 0 {
+			delete(pkg.Files, path)
+		}
+	}
+	return nil
+}
+
+// basicTypes are types that a type placeholder can be constrained to.
+var basicTypes = []types.Type{
+	types.Typ[types.Int],
+	types.Typ[types.Int8],
+	types.Typ[types.Int16],
+	types.Typ[types.Int32],
+	types.Typ[types.Int64],
+	types.Typ[types.Uint],
+	types.Typ[types.Uint8],
+	types.Typ[types.Uint16],
+	types.Typ[types.Uint32],
+	types.Typ[types.Uint64],
+	types.Typ[types.Uintptr],
+	types.Typ[types.Float32],
+	types.Typ[types.Float64],
+	types.Typ[types.Complex64],
+	types.Typ[types.Complex128],
+	types.Typ[types.String],
+}
+
+// buildConstraint prints the constraint of a type placeholder from its interface, its methods, and inferred constraints.
+func (p *migratedPlaceholder) buildConstraint(pkg *Package, constraints []*Constraint) error {
+	var elems []string
+	print := func(node ast.Node) (string, error) {
+		usedImports(pkg.info, node, p.imports)
+		buf := new(bytes.Buffer)
+		err := printer.Fprint(buf, pkg.FileSet, node)
+		if err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	// A constraint with methods must be an interface.
+	var hasMethod bool
+	printMethod := func(name string, t *ast.FuncType) (string, error) {
+		hasMethod = true
+		sig, err := print(t)
+		if err != nil {
+			return "", err
+		}
+		return name + strings.TrimPrefix(sig, "func"), nil
+	}
+
+	// Type set from inferred constraints.
+	allowed := append([]types.Type(nil), basicTypes...)
+	var restricted, comparable bool
+	for _, c := range constraints {
+		switch c.Kind {
+		case constraintComparable:
+			comparable = true
+			continue
+		case constraintRangeable:
+			u, ok := pkg.info.TypeOf(p.spec.Name).Underlying().(*types.Basic)
+			if !ok {
+				return fmt.Errorf("type placeholder %s is %s, but it cannot be expressed as a constraint", p.name, c.Use)
+			}
+			// A type parameter can only be ranged over if its type set has one underlying type.
+			allowed = []types.Type{u}
+			restricted = true
+			continue
+		}
+		restricted = true
+		var next []types.Type
+		for _, t := range allowed {
+			if c.satisfiedBy(t, c.typ) {
+				next = append(next, t)
+			}
+		}
+		allowed = next
+	}
+	if restricted {
+		if len(allowed) == 0 {
+			return fmt.Errorf("no type satisfies constraints of type placeholder %s", p.name)
+		}
+		if isOrderedSet(allowed) {
+			elems = append(elems, "cmp.Ordered")
+			p.imports[types.NewPkgName(token.NoPos, nil, "cmp", types.NewPackage("cmp", "cmp"))] = struct{}{}
+		} else {
+			var union []string
+			for _, t := range allowed {
+				union = append(union, "~"+t.String())
+			}
+			elems = append(elems, strings.Join(union, " | "))
+		}
+	} else if comparable {
+		elems = append(elems, "comparable")
+	}
+
+	if iface, ok := p.spec.Type.(*ast.InterfaceType); ok {
+		for _, field := range iface.Methods.List {
+			if len(field.Names) == 0 {
+				elem, err := print(field.Type)
+				if err != nil {
+					return err
+				}
+				elems = append(elems, elem)
+				continue
+			}
+			for _, name := range field.Names {
+				elem, err := printMethod(name.Name, field.Type.(*ast.FuncType))
+				if err != nil {
+					return err
+				}
+				elems = append(elems, elem)
+			}
+		}
+	}
+	for _, decl := range p.methods {
+		elem, err := printMethod(decl.Name.Name, decl.Type)
+		if err != nil {
+			return err
+		}
+		elems = append(elems, elem)
+	}
+
+	switch {
+	case len(elems) == 0:
+		p.constraint = "any"
+	case len(elems) == 1 && !hasMethod:
+		p.constraint = elems[0]
+	default:
+		p.constraint = "interface{ " + strings.Join(elems, "; ") + " }"
+	}
+	return nil
+}
+
+// isOrderedSet returns true if ts are exactly types in cmp.Ordered.
+func isOrderedSet(ts []types.Type) bool {
+	var n int
+	for _, t := range basicTypes {
+		if t.(*types.Basic).Info()&types.IsOrdered != 0 {
+			n++
+		}
+	}
+	if len(ts) != n {
+		return false
+	}
+	for _, t := range ts {
+		if t.(*types.Basic).Info()&types.IsOrdered == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// checkMigrated type-checks the generic package.
+func checkMigrated(dir, importPath string, pkg *Package) error {
+	var paths []string
+	for path := range pkg.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var files []*ast.File
+	for _, path := range paths {
+		files = append(files, pkg.Files[path])
+	}
+
+	var errType []error
+	conf := types.Config{
+		Importer: newImporter(context.Background(), pkg.FileSet, dir, nil),
+		Error: func(err error) {
+			terr := err.(types.Error)
+			pos := terr.Fset.Position(terr.Pos)
+			pos.Filename = filepath.Base(pos.Filename)
+			errType = append(errType, fmt.Errorf("%s: %s", pos, terr.Msg))
+		},
+	}
+	conf.Check(importPath, pkg.FileSet, files, nil)
+	if len(errType) > 0 {
+		return fmt.Errorf("migrate %s: %w", importPath, errors.Join(errType...))
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+package rewrite
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// monomorphize converts declarations with type parameters to declarations without them.
+//
+// A type parameter is keyed by the declaration that it belongs to in typeMap, like Queue.T.
+// A declaration is converted only if all of its type parameters are in typeMap,
+// and its instantiations in the package are converted to the declaration itself.
+// If the spec is partial, type parameters that are not in typeMap are kept,
+// and they are removed from type parameter lists and instantiations only if they are in typeMap.
+// The constraint of a type parameter becomes the contract of its replacement.
+func (s *Spec) monomorphize(pkg *Package) error {
+	if pkg.info == nil {
+		return nil
+	}
+
+	// typeParams are names of type parameters of each converted declaration,
+	// so that type parameters of methods and instantiations can be found by their position.
+	typeParams := make(map[types.Object][]string)
+	// mapped is whether each type parameter of a converted declaration is in typeMap.
+	mapped := make(map[types.Object][]bool)
+	// paramKey is the typeMap key of each type parameter.
+	paramKey := make(map[types.Object]string)
+	// contracts are by typeMap key, so that explicit type arguments can be checked against the replacement.
+	contracts := make(map[string]*contract)
+	var contractKeys []string
+	found := make(map[string]struct{})
+
+	var paths []string
+	for path := range pkg.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	addDecl := func(node *ast.File, name *ast.Ident, list *ast.FieldList) error {
+		if list == nil {
+			return nil
+		}
+		var names, missing []string
+		var isMapped []bool
+		for _, field := range list.List {
+			for _, ident := range field.Names {
+				key := name.Name + "." + ident.Name
+				names = append(names, ident.Name)
+				_, ok := s.TypeMap[key]
+				if ok {
+					found[key] = struct{}{}
+				} else {
+					missing = append(missing, key)
+				}
+				isMapped = append(isMapped, ok)
+			}
+		}
+		if len(missing) == len(names) {
+			return nil
+		}
+		if len(missing) > 0 && !s.Partial {
+			return fmt.Errorf("spec %s: type parameter %s is not in typeMap", s.Name, strings.Join(missing, ", "))
+		}
+
+		obj := pkg.info.Defs[name]
+		typeParams[obj] = names
+		mapped[obj] = isMapped
+		for _, field := range list.List {
+			for _, ident := range field.Names {
+				key := name.Name + "." + ident.Name
+				if _, ok := s.TypeMap[key]; !ok {
+					continue
+				}
+				paramKey[pkg.info.Defs[ident]] = key
+				c := &contract{
+					name: key,
+					// Imports might be removed from the file later, so they are copied.
+					imports: append([]*ast.ImportSpec(nil), node.Imports...),
+				}
+				if !isAny(field.Type) {
+					c.typeParamConstraint = field.Type
+				}
+				contracts[key] = c
+				contractKeys = append(contractKeys, key)
+			}
+		}
+		return nil
+	}
+	for _, path := range paths {
+		node := pkg.Files[path]
+		for _, decl := range node.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv != nil {
+					continue
+				}
+				err := addDecl(node, decl.Name, decl.Type.TypeParams)
+				if err != nil {
+					return err
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					spec, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					err := addDecl(node, spec.Name, spec.TypeParams)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	var unknown []string
+	for key := range s.TypeMap {
+		if _, ok := found[key]; !ok && strings.Contains(key, ".") {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("spec %s: %s is not a type parameter", s.Name, strings.Join(unknown, ", "))
+	}
+	if len(typeParams) == 0 {
+		return nil
+	}
+
+	// A method declares type parameters of its receiver again, possibly with different names.
+	for _, node := range pkg.Files {
+		for _, decl := range node.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Recv == nil {
+				continue
+			}
+			recv := decl.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			var indices []ast.Expr
+			switch x := recv.(type) {
+			case *ast.IndexExpr:
+				recv, indices = x.X, []ast.Expr{x.Index}
+			case *ast.IndexListExpr:
+				recv, indices = x.X, x.Indices
+			default:
+				continue
+			}
+			ident, ok := recv.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			obj := pkg.info.Uses[ident]
+			names, ok := typeParams[obj]
+			if !ok {
+				continue
+			}
+			for i, index := range indices {
+				index, ok := index.(*ast.Ident)
+				if !ok || i >= len(names) || !mapped[obj][i] {
+					continue
+				}
+				paramKey[pkg.info.Defs[index]] = ident.Name + "." + names[i]
+			}
+		}
+	}
+
+	// Explicit type arguments are dropped, so they must be identical to replacements.
+	addTypeArgs := func(node *ast.File, inst, x ast.Expr, indices []ast.Expr) {
+		ident := x.(*ast.Ident)
+		obj := pkg.info.Uses[ident]
+		for i, index := range indices {
+			if i >= len(mapped[obj]) || !mapped[obj][i] || !concrete(pkg.info, index) {
+				continue
+			}
+			c := contracts[ident.Name+"."+typeParams[obj][i]]
+			c.typeArgs = append(c.typeArgs, &typeArg{
+				expr: index,
+				inst: types.ExprString(inst),
+				arg:  types.ExprString(index),
+			})
+			c.imports = append(c.imports, node.Imports...)
+		}
+	}
+
+	for _, node := range pkg.Files {
+		var imports []string
+		// Imports that are only used by constraints are removed after constraints are dropped.
+		constraintImports := make(map[*types.PkgName]struct{})
+		shrink := func(list *ast.FieldList) *ast.FieldList {
+			var fields []*ast.Field
+			for _, field := range list.List {
+				var names []*ast.Ident
+				for _, ident := range field.Names {
+					if _, ok := paramKey[pkg.info.Defs[ident]]; !ok {
+						names = append(names, ident)
+					}
+				}
+				if len(names) == 0 {
+					usedImports(pkg.info, field.Type, constraintImports)
+					continue
+				}
+				field.Names = names
+				fields = append(fields, field)
+			}
+			if len(fields) == 0 {
+				return nil
+			}
+			list.List = fields
+			return list
+		}
+		astutil.Apply(node, nil, func(c *astutil.Cursor) bool {
+			x, ok := c.Node().(*ast.Ident)
+			if !ok {
+				return true
+			}
+			key, ok := paramKey[pkg.info.Uses[x]]
+			if !ok {
+				return true
+			}
+			replaceTypeExpr(c, s.typeExpr(key, x.Pos()))
+			imports = append(imports, s.TypeMap[key].Import...)
+			return true
+		})
+
+		astutil.Apply(node, nil, func(c *astutil.Cursor) bool {
+			switch x := c.Node().(type) {
+			case *ast.FuncDecl:
+				if _, ok := typeParams[pkg.info.Defs[x.Name]]; ok {
+					x.Type.TypeParams = shrink(x.Type.TypeParams)
+				}
+			case *ast.TypeSpec:
+				if _, ok := typeParams[pkg.info.Defs[x.Name]]; ok {
+					x.TypeParams = shrink(x.TypeParams)
+				}
+			case *ast.IndexExpr:
+				if ok := instantiated(pkg.info, x.X, mapped); ok {
+					addTypeArgs(node, x, x.X, []ast.Expr{x.Index})
+					c.Replace(removeTypeArgs(pkg.info, x.X, []ast.Expr{x.Index}, mapped))
+				}
+			case *ast.IndexListExpr:
+				if ok := instantiated(pkg.info, x.X, mapped); ok {
+					addTypeArgs(node, x, x.X, x.Indices)
+					c.Replace(removeTypeArgs(pkg.info, x.X, x.Indices, mapped))
+				}
+			}
+			return true
+		})
+
+		deleteUnusedImports(pkg.FileSet, pkg.info, node, constraintImports)
+		for _, im := range imports {
+			addImport(pkg.FileSet, node, im)
+		}
+	}
+
+	for _, key := range contractKeys {
+		c := contracts[key]
+		if c.typeParamConstraint != nil || len(c.typeArgs) > 0 {
+			pkg.contracts = append(pkg.contracts, c)
+		}
+	}
+	return nil
+}
+
+// usedImports adds imported packages that are used in node to pkgNames.
+func usedImports(info *types.Info, node ast.Node, pkgNames map[*types.PkgName]struct{}) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		if x, ok := n.(*ast.Ident); ok {
+			if pkgName, ok := info.Uses[x].(*types.PkgName); ok {
+				pkgNames[pkgName] = struct{}{}
+			}
+		}
+		return true
+	})
+}
+
+// deleteUnusedImports deletes imports of pkgNames that are no longer used in node.
+func deleteUnusedImports(fset *token.FileSet, info *types.Info, node *ast.File, pkgNames map[*types.PkgName]struct{}) {
+	used := make(map[*types.PkgName]struct{})
+	usedImports(info, node, used)
+	for pkgName := range pkgNames {
+		if _, ok := used[pkgName]; ok {
+			continue
+		}
+		path := pkgName.Imported().Path()
+		name := ""
+		for _, spec := range node.Imports {
+			if spec.Name != nil && strings.Trim(spec.Path.Value, `"`) == path {
+				name = spec.Name.Name
+			}
+		}
+		astutil.DeleteNamedImport(fset, node, name, path)
+	}
+}
+
+// instantiated returns true if expr is a declaration that is converted.
+func instantiated(info *types.Info, expr ast.Expr, mapped map[types.Object][]bool) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	_, ok = mapped[info.Uses[ident]]
+	return ok
+}
+
+// removeTypeArgs removes type arguments of type parameters that are in typeMap from an instantiation.
+func removeTypeArgs(info *types.Info, expr ast.Expr, indices []ast.Expr, mapped map[types.Object][]bool) ast.Expr {
+	isMapped := mapped[info.Uses[expr.(*ast.Ident)]]
+	var kept []ast.Expr
+	for i, index := range indices {
+		if i < len(isMapped) && isMapped[i] {
+			continue
+		}
+		kept = append(kept, index)
+	}
+	switch len(kept) {
+	case 0:
+		return expr
+	case 1:
+		return &ast.IndexExpr{X: expr, Lbrack: expr.End(), Index: kept[0], Rbrack: kept[0].End()}
+	}
+	return &ast.IndexListExpr{X: expr, Lbrack: expr.End(), Indices: kept, Rbrack: kept[len(kept)-1].End()}
+}
+
+// concrete returns true if a type argument does not use type parameters.
+//
+// Type parameters that are already replaced are not type-checked, so they are not concrete either.
+func concrete(info *types.Info, expr ast.Expr) bool {
+	ok := true
+	ast.Inspect(expr, func(n ast.Node) bool {
+		x, isIdent := n.(*ast.Ident)
+		if !isIdent || !ok {
+			return ok
+		}
+		obj, found := info.Uses[x]
+		if !found {
+			ok = false
+		} else if _, isParam := obj.Type().(*types.TypeParam); isParam {
+			ok = false
+		}
+		return ok
+	})
+	return ok
+}
+
+// isAny returns true if a constraint allows any type.
+func isAny(expr ast.Expr) bool {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return x.Name == "any"
+	case *ast.InterfaceType:
+		return len(x.Methods.List) == 0
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package rewrite
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const generatedComment = "// Code generated by gorewrite. DO NOT EDIT."
+
+// Origin describes where a generated file comes from.
+//
+// It is written at the beginning of every generated file.
+type Origin struct {
+	// Spec is the spec name.
+	Spec string
+	// Import is the package that it is rewritten from.
+	Import string
+	// Template is the directory of the package that it is rewritten from, if it is not imported.
+	Template string
+	// Source is the file name in that package.
+	Source string
+	// TypeMap maps type placeholders to their replacement as it is configured,
+	// before its imports are inferred and its package qualifiers are rewritten.
+	TypeMap map[string]Type
+}
+
+func (s *Spec) origin(source string) *Origin {
+	typeMap := make(map[string]Type)
+	for name, to := range s.TypeMap {
+		typeMap[name] = Type{Expr: to.Expr, Import: slices.Clone(to.Import)}
+	}
+	return &Origin{
+		Spec:     s.Name,
+		Import:   s.Import,
+		Template: filepath.ToSlash(s.Template),
+		Source:   source,
+		TypeMap:  typeMap,
+	}
+}
+
+// Header returns comments that begin a generated file.
+func (o *Origin) Header() []byte {
+	buf := new(bytes.Buffer)
+	fmt.Fprintln(buf, generatedComment)
+	fmt.Fprintln(buf, "//")
+	fmt.Fprintf(buf, "// Spec: %s\n", o.Spec)
+	switch {
+	case o.Import != "":
+		fmt.Fprintf(buf, "// Import: %s\n", o.Import)
+	case o.Template != "":
+		fmt.Fprintf(buf, "// Template: %s\n", o.Template)
+	}
+	fmt.Fprintf(buf, "// Source: %s\n", o.Source)
+	fmt.Fprintln(buf, "// TypeMap:")
+	var names []string
+	for name := range o.TypeMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		to := o.TypeMap[name]
+		fmt.Fprintf(buf, "//\t%s: %s\n", name, to.Expr)
+		for _, im := range to.Import {
+			fmt.Fprintf(buf, "//\t\tImport: %s\n", im)
+		}
+	}
+	fmt.Fprintln(buf)
+	return buf.Bytes()
+}
+
+// ReadOrigin reads the header of a generated file.
+func ReadOrigin(src []byte) (*Origin, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	if !scanner.Scan() || scanner.Text() != generatedComment {
+		return nil, errors.New("file is not generated by gorewrite")
+	}
+	o := &Origin{TypeMap: make(map[string]Type)}
+	var (
+		typeMap bool
+		last    string
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "//") {
+			break
+		}
+		line = strings.TrimPrefix(line, "//")
+		if typeMap && strings.HasPrefix(line, "\t\t") {
+			im, ok := strings.CutPrefix(line, "\t\tImport: ")
+			if !ok || last == "" {
+				return nil, fmt.Errorf("invalid type mapping %q", line)
+			}
+			to := o.TypeMap[last]
+			to.Import = append(to.Import, im)
+			o.TypeMap[last] = to
+			continue
+		}
+		if typeMap && strings.HasPrefix(line, "\t") {
+			part := strings.SplitN(strings.TrimPrefix(line, "\t"), ": ", 2)
+			if len(part) != 2 {
+				return nil, fmt.Errorf("invalid type mapping %q", line)
+			}
+			o.TypeMap[part[0]] = Type{Expr: part[1]}
+			last = part[0]
+			continue
+		}
+		typeMap = false
+		part := strings.SplitN(strings.TrimPrefix(line, " "), ":", 2)
+		if len(part) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(part[1])
+		switch part[0] {
+		case "Spec":
+			o.Spec = value
+		case "Import":
+			o.Import = value
+		case "Template":
+			o.Template = value
+		case "Source":
+			o.Source = value
+		case "TypeMap":
+			typeMap = true
+		}
+	}
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+	if o.Spec == "" {
+		return nil, errors.New("spec is not found in header")
+	}
+	return o, nil
+}
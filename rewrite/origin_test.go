@@ -0,0 +1,41 @@
+package rewrite
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadOrigin(t *testing.T) {
+	o := &Origin{
+		Spec:   "result",
+		Import: "github.com/taylorchu/generic/rewrite/_test/pkg/queue",
+		Source: "queue.go",
+		TypeMap: map[string]Type{
+			"Type": Type{
+				Expr:   "map[string]*vendoring.Number",
+				Import: []string{"github.com/taylorchu/generic/rewrite/_test/pkg/vendoring"},
+			},
+			"TypeQueue": Type{Expr: "FIFO"},
+		},
+	}
+	src := append(o.Header(), "package result\n"...)
+	got, err := ReadOrigin(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(o, got) {
+		t.Fatalf("expect %+v, got %+v", o, got)
+	}
+
+	for _, src := range []string{
+		"",
+		"package result\n",
+		"// Code generated by other. DO NOT EDIT.\n\npackage result\n",
+		"// Code generated by gorewrite. DO NOT EDIT.\n\npackage result\n",
+	} {
+		_, err := ReadOrigin([]byte(src))
+		if err == nil {
+			t.Fatalf("expect error for %q", src)
+		}
+	}
+}
@@ -6,16 +6,46 @@ import (
 	"go/parser"
 	"go/printer"
 	"go/token"
+	"go/types"
 	"os"
 )
 
 type Package struct {
 	Files   map[string]*ast.File
 	FileSet *token.FileSet
+
+	// types and info are from type-checking the package that it is rewritten from.
+	// They are no longer valid after Reset.
+	types *types.Package
+	info  *types.Info
+
+	// contracts are from type placeholders that are removed.
+	contracts []*contract
+}
+
+// Types returns the type-checked package, or nil if the AST is changed after it is type-checked.
+func (p *Package) Types() *types.Package {
+	return p.types
+}
+
+// TypesInfo returns type information of the AST, or nil if the AST is changed after it is type-checked.
+func (p *Package) TypesInfo() *types.Info {
+	return p.info
+}
+
+// objectOf returns the object that ident refers to.
+//
+// Unlike types.Info.ObjectOf, an embedded field refers to its type instead of the field.
+func (p *Package) objectOf(ident *ast.Ident) types.Object {
+	if obj, ok := p.info.Uses[ident]; ok {
+		return obj
+	}
+	return p.info.Defs[ident]
 }
 
 func (p *Package) Reset() error {
-	p.FileSet = token.NewFileSet()
+	// Print with the old file set so that comments stay next to the nodes they describe.
+	fset := token.NewFileSet()
 	buf := new(bytes.Buffer)
 	for name, f := range p.Files {
 		buf.Reset()
@@ -23,18 +53,15 @@ func (p *Package) Reset() error {
 		if err != nil {
 			return err
 		}
-		parsed, err := parser.ParseFile(p.FileSet, "", buf, 0)
+		parsed, err := parser.ParseFile(fset, name, buf, parser.ParseComments|parser.SkipObjectResolution)
 		if err != nil {
 			printer.Fprint(os.Stderr, p.FileSet, f)
 			return err
 		}
 		p.Files[name] = parsed
 	}
-
-	// Gather ast.File to create ast.Package.
-	// ast.NewPackage will try to resolve unresolved identifiers.
-	//
-	// It will return errors because the importer is not provided.
-	ast.NewPackage(p.FileSet, p.Files, nil, nil)
+	p.FileSet = fset
+	p.types = nil
+	p.info = nil
 	return nil
 }
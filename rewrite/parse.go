@@ -1,32 +1,140 @@
 package rewrite
 
 import (
+	"context"
+	"fmt"
 	"go/ast"
 	"go/build"
 	"go/parser"
 	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
+	"os"
+	"path"
 	"path/filepath"
+	"testing/fstest"
+
+	"golang.org/x/tools/go/packages"
 )
 
-func (s *Spec) parse() (*Package, error) {
+// parse loads the package that it is rewritten from, as if it is imported from dir.
+func (s *Spec) parse(ctx context.Context, dir string) (*Package, error) {
+	var n int
+	for _, set := range []bool{s.Import != "", s.Template != "", s.FS != nil, s.Source != nil} {
+		if set {
+			n++
+		}
+	}
+	switch {
+	case n == 0:
+		return nil, fmt.Errorf("spec %s: import cannot be empty", s.Name)
+	case n > 1:
+		return nil, fmt.Errorf("spec %s: only one of import, template, fs and source can be set", s.Name)
+	case filepath.IsAbs(s.Template):
+		// It is recorded in generated files, so it cannot depend on where the config is.
+		return nil, fmt.Errorf("spec %s: template %s must be relative to the config directory", s.Name, s.Template)
+	}
+
+	switch {
+	case s.Template != "":
+		return s.parseFS(ctx, dir, os.DirFS(filepath.Join(dir, s.Template)), filepath.Join(dir, s.Template))
+	case s.FS != nil:
+		return s.parseFS(ctx, dir, s.FS, "")
+	case s.Source != nil:
+		fsys := make(fstest.MapFS)
+		for name, src := range s.Source {
+			fsys[name] = &fstest.MapFile{Data: []byte(src)}
+		}
+		return s.parseFS(ctx, dir, fsys, "")
+	}
+
 	// NOTE: this package that we try to rewrite from should not contain vendor/.
-	buildP, err := build.Import(s.Import, "", 0)
+	fset := token.NewFileSet()
+	loadP, err := loadPackage(ctx, fset, dir, s.Import, packages.NeedName|packages.NeedFiles|packages.NeedSyntax|packages.NeedTypes|packages.NeedTypesInfo, nil)
 	if err != nil {
 		return nil, err
 	}
+	files := make(map[string]*ast.File)
+	for _, f := range loadP.Syntax {
+		files[fset.Position(f.Package).Filename] = f
+	}
+	return &Package{
+		Files:   files,
+		FileSet: fset,
+		types:   loadP.Types,
+		info:    loadP.TypesInfo,
+	}, nil
+}
+
+// parseFS loads the package that it is rewritten from in the root of fsys.
+//
+// Files are selected by build constraints like the go command does, and its imports are resolved from dir.
+// File names are joined with root, so they can be found on disk if fsys is a directory.
+func (s *Spec) parseFS(ctx context.Context, dir string, fsys fs.FS, root string) (*Package, error) {
+	bctx := build.Default
+	bctx.JoinPath = path.Join
+	bctx.IsDir = func(name string) bool {
+		fi, err := fs.Stat(fsys, name)
+		return err == nil && fi.IsDir()
+	}
+	bctx.ReadDir = func(name string) ([]fs.FileInfo, error) {
+		entries, err := fs.ReadDir(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		var fi []fs.FileInfo
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			fi = append(fi, info)
+		}
+		return fi, nil
+	}
+	bctx.OpenFile = func(name string) (io.ReadCloser, error) {
+		return fsys.Open(name)
+	}
+	buildP, err := bctx.ImportDir(".", 0)
+	if err != nil {
+		return nil, fmt.Errorf("spec %s: %w", s.Name, err)
+	}
+
 	fset := token.NewFileSet()
 	files := make(map[string]*ast.File)
-	for _, file := range buildP.GoFiles {
-		path := filepath.Join(buildP.Dir, file)
-		f, err := parser.ParseFile(fset, path, nil, 0)
+	for _, name := range buildP.GoFiles {
+		src, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(root, name), src, parser.ParseComments|parser.SkipObjectResolution)
 		if err != nil {
 			return nil, err
 		}
-		files[path] = f
+		files[filepath.Join(root, name)] = f
 	}
-	ast.NewPackage(fset, files, nil, nil)
-	return &Package{
+	pkg := &Package{
 		Files:   files,
 		FileSet: fset,
-	}, nil
+	}
+	checkTemplate(ctx, dir, buildP.Name, pkg)
+	return pkg, nil
+}
+
+// checkTemplate type-checks the package that it is rewritten from with the import path, and keeps type information in pkg.
+//
+// Type errors are not returned because type information is still useful.
+func checkTemplate(ctx context.Context, dir, importPath string, pkg *Package) {
+	var syntax []*ast.File
+	for _, path := range sortedKeys(pkg.Files) {
+		syntax = append(syntax, pkg.Files[path])
+	}
+	conf := &types.Config{
+		Importer: newImporter(ctx, pkg.FileSet, dir, nil),
+		Error:    func(error) {},
+	}
+	info := newTypesInfo()
+	pkg.types, _ = conf.Check(importPath, pkg.FileSet, syntax, info)
+	pkg.info = info
 }
@@ -0,0 +1,30 @@
+package rewrite
+
+import "fmt"
+
+// Pass changes a package while a spec is rewritten.
+//
+// Passes before built-in ones get the package that it is rewritten from, which is type-checked again after them,
+// and passes after them get the rewritten package, which is type-checked again before it is written.
+// Either way, type information of the package is available until the AST is changed.
+type Pass interface {
+	Run(s *Spec, pkg *Package) error
+}
+
+// PassFunc is a function that is used as a Pass.
+type PassFunc func(s *Spec, pkg *Package) error
+
+func (f PassFunc) Run(s *Spec, pkg *Package) error {
+	return f(s, pkg)
+}
+
+// runPasses runs custom passes, and reports errors with the spec name.
+func (s *Spec) runPasses(pkg *Package, passes []Pass) error {
+	for _, pass := range passes {
+		err := pass.Run(s, pkg)
+		if err != nil {
+			return fmt.Errorf("spec %s: %w", s.Name, err)
+		}
+	}
+	return nil
+}
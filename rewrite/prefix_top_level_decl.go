@@ -3,11 +3,12 @@ package rewrite
 import (
 	"fmt"
 	"go/ast"
+	"go/types"
 )
 
 // prefixTopLevelDecl adds a prefix to top-level identifiers and their uses.
 //
-// This prevents name conflicts when a package is rewritten to $PWD.
+// This prevents name conflicts when a package is rewritten to an existing package.
 func (s *Spec) prefixTopLevelDecl(pkg *Package) error {
 	if !s.Local {
 		return nil
@@ -21,7 +22,17 @@ func (s *Spec) prefixTopLevelDecl(pkg *Package) error {
 		return lintName(fmt.Sprintf("%s_%s", s.Name, name))
 	}
 
-	declMap := make(map[interface{}]string)
+	declMap := make(map[types.Object]string)
+	// The doc comment that describes the identifier starts with the new name too.
+	prefix := func(ident *ast.Ident, doc *ast.CommentGroup) {
+		obj := pkg.info.Defs[ident]
+		name := prefixIdent(ident.Name)
+		renameDoc(doc, "", ident.Name, name)
+		ident.Name = name
+		if obj != nil {
+			declMap[obj] = ident.Name
+		}
+	}
 
 	for _, node := range pkg.Files {
 		for _, decl := range node.Decls {
@@ -30,25 +41,31 @@ func (s *Spec) prefixTopLevelDecl(pkg *Package) error {
 				if decl.Recv != nil {
 					continue
 				}
-				decl.Name.Name = prefixIdent(decl.Name.Name)
-				declMap[decl] = decl.Name.Name
+				prefix(decl.Name, decl.Doc)
 			case *ast.GenDecl:
 				for _, spec := range decl.Specs {
+					doc := decl.Doc
+					if decl.Lparen.IsValid() {
+						doc = nil
+					}
 					switch spec := spec.(type) {
 					case *ast.TypeSpec:
-						obj := spec.Name.Obj
-						if obj != nil && obj.Kind == ast.Typ {
-							if to, ok := s.TypeMap[obj.Name]; ok && spec.Name.Name == to.Expr {
+						if obj := pkg.info.Defs[spec.Name]; obj != nil {
+							if _, ok := s.TypeMap[obj.Name()]; ok {
 								// If this identifier is already rewritten before, we don't need to prefix it.
 								continue
 							}
 						}
-						spec.Name.Name = prefixIdent(spec.Name.Name)
-						declMap[spec] = spec.Name.Name
+						if spec.Doc != nil {
+							doc = spec.Doc
+						}
+						prefix(spec.Name, doc)
 					case *ast.ValueSpec:
+						if spec.Doc != nil {
+							doc = spec.Doc
+						}
 						for _, ident := range spec.Names {
-							ident.Name = prefixIdent(ident.Name)
-							declMap[spec] = ident.Name
+							prefix(ident, doc)
 						}
 					}
 				}
@@ -57,22 +74,25 @@ func (s *Spec) prefixTopLevelDecl(pkg *Package) error {
 	}
 
 	// After top-level identifiers are renamed, find where they are used, and rewrite those.
-	for _, node := range pkg.Files {
-		ast.Inspect(node, func(n ast.Node) bool {
-			switch x := n.(type) {
-			case *ast.Ident:
-				if x.Obj == nil || x.Obj.Decl == nil {
-					return false
-				}
-				name, ok := declMap[x.Obj.Decl]
-				if !ok {
-					return false
-				}
-				x.Name = name
+	rename := func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.Ident:
+			name, ok := declMap[pkg.info.Uses[x]]
+			if !ok {
 				return false
 			}
-			return true
-		})
+			x.Name = name
+			return false
+		}
+		return true
+	}
+	for _, node := range pkg.Files {
+		ast.Inspect(node, rename)
+	}
+	for _, c := range pkg.contracts {
+		for _, node := range c.nodes() {
+			ast.Inspect(node, rename)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,104 @@
+package rewrite
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// qualifiedType matches a type that is qualified by the import path of its package, like github.com/acme/geo.Point.
+// An import path is told apart from a package name by its slash.
+var qualifiedType = regexp.MustCompile(`(^|[^\w./~-])([\w.~-]+(?:/[\w.~-]+)+)\.([A-Za-z_]\w*)`)
+
+// qualifiedImports returns import paths that qualify types in expr.
+func qualifiedImports(expr string) []string {
+	var paths []string
+	for _, m := range qualifiedType.FindAllStringSubmatch(expr, -1) {
+		paths = append(paths, m[2])
+	}
+	return paths
+}
+
+// qualifyTypeMap returns a copy of the spec whose typeMap refers to packages by name instead of import path.
+//
+// Import paths are added to import. If a package name is already used by another package in the same entry,
+// the package is imported with a unique alias.
+func (s *Spec) qualifyTypeMap(importer types.Importer) *Spec {
+	typeMap := make(map[string]Type)
+	for key, to := range s.TypeMap {
+		typeMap[key] = to
+		matches := qualifiedType.FindAllStringSubmatchIndex(to.Expr, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		packageName := func(path string) string {
+			if pkg, err := importer.Import(path); err == nil {
+				return pkg.Name()
+			}
+			return defaultImportName(path)
+		}
+
+		// Names that are taken by imports, and package names that are used in expr.
+		used := make(map[string]string)
+		byPath := make(map[string]string)
+		for _, im := range to.Import {
+			name, path := splitImport(im)
+			if name == "" {
+				name = packageName(path)
+			}
+			used[name] = path
+			byPath[path] = name
+		}
+		const placeholder = "gorewriteQualified"
+		if expr, err := parser.ParseExpr(qualifiedType.ReplaceAllString(to.Expr, "${1}"+placeholder+".${3}")); err == nil {
+			ast.Inspect(expr, func(n ast.Node) bool {
+				if x, ok := n.(*ast.SelectorExpr); ok {
+					if qual, ok := x.X.(*ast.Ident); ok && qual.Name != placeholder {
+						if _, ok := used[qual.Name]; !ok {
+							used[qual.Name] = ""
+						}
+					}
+				}
+				return true
+			})
+		}
+
+		to.Import = append([]string(nil), to.Import...)
+		var b strings.Builder
+		var last int
+		for _, m := range matches {
+			path := to.Expr[m[4]:m[5]]
+			name, ok := byPath[path]
+			if !ok {
+				base := packageName(path)
+				name = base
+				for i := 2; ; i++ {
+					if _, ok := used[name]; !ok {
+						break
+					}
+					name = base + strconv.Itoa(i)
+				}
+				used[name] = path
+				byPath[path] = name
+				if name == base {
+					to.Import = append(to.Import, path)
+				} else {
+					to.Import = append(to.Import, name+" "+path)
+				}
+			}
+			b.WriteString(to.Expr[last:m[4]])
+			b.WriteString(name)
+			last = m[5]
+		}
+		b.WriteString(to.Expr[last:])
+		to.Expr = b.String()
+		typeMap[key] = to
+	}
+
+	spec := *s
+	spec.TypeMap = typeMap
+	return &spec
+}
@@ -2,37 +2,73 @@ package rewrite
 
 import (
 	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
 )
 
 // removePlaceholder removes type declarations defined in typeMap.
-func (s *Spec) removePlaceholder(pkg *Package) error {
-	declMap := make(map[interface{}]struct{})
+//
+// A type placeholder is removed if it is declared with an identifier like `type Type int`, or an interface.
+// The interface, or methods on the type placeholder become its contract,
+// together with constraints inferred from how it is used.
+// An interface is renamed instead if its replacement is a new name, which is not in declared or predeclared.
+func (s *Spec) removePlaceholder(pkg *Package, declared map[string]struct{}) error {
+	declMap := make(map[types.Object]*contract)
 	for _, node := range pkg.Files {
 		for i := len(node.Decls) - 1; i >= 0; i-- {
 			var remove bool
 			switch decl := node.Decls[i].(type) {
 			case *ast.GenDecl:
+				var specs []ast.Spec
 				for _, spec := range decl.Specs {
 					switch spec := spec.(type) {
 					case *ast.TypeSpec:
 						_, ok := s.TypeMap[spec.Name.Name]
-						if !ok {
+						if !ok || !isPlaceholder(spec) || s.renamesInterface(spec, declared) {
+							specs = append(specs, spec)
 							continue
 						}
-						_, ok = spec.Type.(*ast.Ident)
-						if !ok {
-							continue
+						c := &contract{name: spec.Name.Name}
+						if t, ok := spec.Type.(*ast.InterfaceType); ok {
+							c.iface = t
+							c.imports = node.Imports
 						}
-						remove = true
-						declMap[spec] = struct{}{}
+						declMap[pkg.info.Defs[spec.Name]] = c
+						pkg.contracts = append(pkg.contracts, c)
+					default:
+						specs = append(specs, spec)
 					}
 				}
+				if len(specs) == 0 {
+					remove = true
+				} else if len(specs) < len(decl.Specs) {
+					// Other types in the same group are kept.
+					for _, spec := range decl.Specs {
+						if !slices.Contains(specs, spec) {
+							removeComments(pkg.FileSet, node, spec)
+						}
+					}
+					decl.Specs = specs
+				}
 			}
 			if remove {
+				removeComments(pkg.FileSet, node, node.Decls[i])
 				node.Decls = append(node.Decls[:i], node.Decls[i+1:]...)
 			}
 		}
 	}
+	names := make(map[string]struct{})
+	for _, c := range pkg.contracts {
+		names[c.name] = struct{}{}
+	}
+	for _, constraint := range inferConstraints(pkg, names) {
+		for _, c := range pkg.contracts {
+			if c.name == constraint.Placeholder {
+				c.constraints = append(c.constraints, constraint)
+			}
+		}
+	}
 	// If a type placeholder is removed, its linked methods should be removed too.
 	// This works like go interface because now the replaced types need to implement these methods.
 	for _, node := range pkg.Files {
@@ -43,26 +79,109 @@ func (s *Spec) removePlaceholder(pkg *Package) error {
 				if decl.Recv == nil {
 					continue
 				}
-				var obj *ast.Object
-				switch expr := decl.Recv.List[0].Type.(type) {
-				case *ast.StarExpr:
-					obj = expr.X.(*ast.Ident).Obj
-				case *ast.Ident:
-					obj = expr.Obj
+				expr := decl.Recv.List[0].Type
+				if star, ok := expr.(*ast.StarExpr); ok {
+					expr = star.X
 				}
-				if obj == nil || obj.Decl == nil {
+				ident, ok := expr.(*ast.Ident)
+				if !ok {
 					continue
 				}
-				_, ok := declMap[obj.Decl]
+				c, ok := declMap[pkg.info.Uses[ident]]
 				if !ok {
 					continue
 				}
+				c.addMethod(node, decl)
 				remove = true
 			}
 			if remove {
+				removeComments(pkg.FileSet, node, node.Decls[i])
 				node.Decls = append(node.Decls[:i], node.Decls[i+1:]...)
 			}
 		}
 	}
 	return nil
 }
+
+// renamesInterface returns true if a type placeholder is an interface that is renamed to a new name.
+func (s *Spec) renamesInterface(spec *ast.TypeSpec, declared map[string]struct{}) bool {
+	if _, ok := spec.Type.(*ast.InterfaceType); !ok {
+		return false
+	}
+	ident, ok := s.typeExpr(spec.Name.Name, token.NoPos).(*ast.Ident)
+	if !ok || types.Universe.Lookup(ident.Name) != nil {
+		return false
+	}
+	_, ok = declared[ident.Name]
+	return !ok
+}
+
+// localDecls returns names of top-level declarations in the output directory of a local spec.
+func (s *Spec) localDecls(st *stage, pkg *Package, hidden map[string]struct{}) (map[string]struct{}, error) {
+	declared := make(map[string]struct{})
+	if !s.Local {
+		return declared, nil
+	}
+	outputPath := make(map[string]string)
+	for path := range pkg.Files {
+		outputPath[path] = s.outputPath(path)
+	}
+	files, err := s.parseLocal(st, pkg, outputPath, hidden)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						declared[spec.Name.Name] = struct{}{}
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							declared[name.Name] = struct{}{}
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					declared[decl.Name.Name] = struct{}{}
+				}
+			}
+		}
+	}
+	return declared, nil
+}
+
+// removeComments removes comments of a declaration or spec that is about to be removed.
+//
+// Otherwise they are printed next to whatever node ends up at their position.
+func removeComments(fset *token.FileSet, node *ast.File, decl ast.Node) {
+	start := decl.Pos()
+	switch decl := decl.(type) {
+	case *ast.GenDecl:
+		if decl.Doc != nil {
+			start = decl.Doc.Pos()
+		}
+	case *ast.FuncDecl:
+		if decl.Doc != nil {
+			start = decl.Doc.Pos()
+		}
+	case *ast.TypeSpec:
+		if decl.Doc != nil {
+			start = decl.Doc.Pos()
+		}
+	}
+	end := decl.End()
+	endLine := fset.Position(end).Line
+
+	comments := node.Comments[:0]
+	for _, cg := range node.Comments {
+		if cg.Pos() >= start && (cg.End() <= end || fset.Position(cg.Pos()).Line == endLine) {
+			continue
+		}
+		comments = append(comments, cg)
+	}
+	node.Comments = comments
+}
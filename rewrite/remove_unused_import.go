@@ -0,0 +1,83 @@
+package rewrite
+
+import (
+	"go/ast"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// removeUnusedImports removes imports that are no longer used after rewriting, and declarations that become empty.
+//
+// An import is often left unused after a type placeholder and its methods are removed.
+// Blank and dot imports are kept because they are not used by name.
+func (s *Spec) removeUnusedImports(pkg *Package) error {
+	for _, node := range pkg.Files {
+		used := make(map[*types.PkgName]struct{})
+		usedImports(pkg.info, node, used)
+		// Replacements are not type-checked yet, so packages that they use are found by name.
+		usedNames := make(map[string]struct{})
+		ast.Inspect(node, func(n ast.Node) bool {
+			if x, ok := n.(*ast.SelectorExpr); ok {
+				if ident, ok := x.X.(*ast.Ident); ok && pkg.objectOf(ident) == nil {
+					usedNames[ident.Name] = struct{}{}
+				}
+			}
+			return true
+		})
+
+		for _, spec := range append([]*ast.ImportSpec(nil), node.Imports...) {
+			var name string
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			if name == "_" || name == "." {
+				continue
+			}
+			pkgName := importedPkgName(pkg.info, spec)
+			if pkgName == nil {
+				// Imports of replacements are not type-checked yet.
+				continue
+			}
+			if _, ok := used[pkgName]; ok {
+				continue
+			}
+			if _, ok := usedNames[pkgName.Name()]; ok {
+				continue
+			}
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return err
+			}
+			astutil.DeleteNamedImport(pkg.FileSet, node, name, path)
+		}
+
+		decls := node.Decls[:0]
+		for _, decl := range node.Decls {
+			if decl, ok := decl.(*ast.GenDecl); ok && len(decl.Specs) == 0 {
+				if decl.Rparen.IsValid() {
+					removeComments(pkg.FileSet, node, decl)
+				} else if decl.Doc != nil {
+					removeComments(pkg.FileSet, node, decl.Doc)
+				}
+				continue
+			}
+			decls = append(decls, decl)
+		}
+		node.Decls = decls
+	}
+	return nil
+}
+
+// importedPkgName returns the package name that an import declares.
+func importedPkgName(info *types.Info, spec *ast.ImportSpec) *types.PkgName {
+	var obj types.Object
+	if spec.Name != nil {
+		obj = info.Defs[spec.Name]
+	} else {
+		obj = info.Implicits[spec]
+	}
+	pkgName, _ := obj.(*types.PkgName)
+	return pkgName
+}
@@ -0,0 +1,146 @@
+package rewrite
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+)
+
+// rewriteEmbeddedField renames embedded fields of types in typeMap after their replacements.
+//
+// The name of an embedded field is implied by its type, so selectors and keys of composite literals
+// that refer to the field are renamed with it.
+// If the replacement cannot be embedded, the field is named after the type that it replaces,
+// unless fields or methods that are promoted from it are used.
+func (s *Spec) rewriteEmbeddedField(pkg *Package) error {
+	rename := make(map[types.Object]string)
+	var err error
+	for _, path := range sortedKeys(pkg.Files) {
+		ast.Inspect(pkg.Files[path], func(n ast.Node) bool {
+			if err != nil {
+				return false
+			}
+			if st, ok := n.(*ast.StructType); ok {
+				err = s.rewriteEmbeddedFieldIn(pkg, st, rename)
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(rename) == 0 {
+		return nil
+	}
+
+	for _, node := range pkg.Files {
+		ast.Inspect(node, func(n ast.Node) bool {
+			if x, ok := n.(*ast.Ident); ok {
+				if name, ok := rename[pkg.info.Uses[x]]; ok {
+					x.Name = name
+				}
+			}
+			return true
+		})
+	}
+	return nil
+}
+
+// rewriteEmbeddedFieldIn finds embedded fields of types in typeMap in st, and adds those to rename.
+func (s *Spec) rewriteEmbeddedFieldIn(pkg *Package, st *ast.StructType, rename map[types.Object]string) error {
+	for _, field := range st.Fields.List {
+		if len(field.Names) > 0 {
+			continue
+		}
+		typ, ptr := field.Type, false
+		if star, ok := typ.(*ast.StarExpr); ok {
+			typ, ptr = star.X, true
+		}
+		ident, ok := typ.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		obj, ok := pkg.objectOf(ident).(*types.TypeName)
+		if !ok || obj.Parent() != pkg.types.Scope() {
+			continue
+		}
+		to, ok := s.TypeMap[obj.Name()]
+		if !ok {
+			continue
+		}
+		fieldObj := pkg.info.Defs[ident]
+		if fieldObj == nil {
+			continue
+		}
+		if name, ok := embeddedName(s.typeExpr(obj.Name(), token.NoPos), ptr); ok {
+			if name != fieldObj.Name() {
+				rename[fieldObj] = name
+			}
+			continue
+		}
+		if sel := promotedUse(pkg, fieldObj); sel != nil {
+			pos := pkg.FileSet.Position(sel.Sel.Pos())
+			pos.Filename = filepath.Base(pos.Filename)
+			return fmt.Errorf("spec %s: %s cannot be embedded in place of %s, but %s is promoted from it at %s", s.Name, to.Expr, obj.Name(), sel.Sel.Name, pos)
+		}
+		// Keep the field name, so that selectors and keys stay the same.
+		field.Names = []*ast.Ident{ast.NewIdent(fieldObj.Name())}
+	}
+	return nil
+}
+
+// embeddedName returns the name of an embedded field of type expr.
+//
+// If the embedded field is a pointer already, expr is used as its element type.
+func embeddedName(expr ast.Expr, ptr bool) (string, bool) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		if ptr {
+			return "", false
+		}
+		expr = star.X
+	}
+	switch x := expr.(type) {
+	case *ast.IndexExpr:
+		expr = x.X
+	case *ast.IndexListExpr:
+		expr = x.X
+	}
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return x.Name, true
+	case *ast.SelectorExpr:
+		if _, ok := x.X.(*ast.Ident); ok {
+			return x.Sel.Name, true
+		}
+	}
+	return "", false
+}
+
+// promotedUse returns the first selector that uses a field or method promoted from an embedded field.
+func promotedUse(pkg *Package, field types.Object) *ast.SelectorExpr {
+	var first *ast.SelectorExpr
+	for expr, sel := range pkg.info.Selections {
+		t := sel.Recv()
+		index := sel.Index()
+		for _, i := range index[:len(index)-1] {
+			if ptr, ok := t.Underlying().(*types.Pointer); ok {
+				t = ptr.Elem()
+			}
+			st, ok := t.Underlying().(*types.Struct)
+			if !ok {
+				break
+			}
+			f := st.Field(i)
+			if f == field {
+				if first == nil || expr.Pos() < first.Pos() {
+					first = expr
+				}
+				break
+			}
+			t = f.Type()
+		}
+	}
+	return first
+}
@@ -1,7 +1,11 @@
 package rewrite
 
 import (
+	"fmt"
 	"go/ast"
+	"go/types"
+	"regexp"
+	"strings"
 
 	"golang.org/x/tools/go/ast/astutil"
 )
@@ -9,25 +13,141 @@ import (
 // rewriteIdent converts TypeXXX to its replacement defined in typeMap.
 func (s *Spec) rewriteIdent(pkg *Package) error {
 	for _, node := range pkg.Files {
-		ast.Inspect(node, func(n ast.Node) bool {
-			switch x := n.(type) {
-			case *ast.Ident:
-				if x.Obj == nil || x.Obj.Kind != ast.Typ {
-					return false
-				}
-				to, ok := s.TypeMap[x.Name]
-				if !ok {
-					return false
-				}
-				x.Name = to.Expr
+		// Imports are added after inspection because adding them changes node.Decls.
+		_, imports, err := s.rewriteIdentIn(pkg, node)
+		if err != nil {
+			return err
+		}
+		for _, im := range imports {
+			addImport(pkg.FileSet, node, im)
+		}
 
-				for _, im := range to.Import {
-					astutil.AddImport(pkg.FileSet, node, im)
+		for _, cg := range node.Comments {
+			for _, c := range cg.List {
+				c.Text = s.rewriteComment(c.Text)
+			}
+		}
+	}
+	for _, c := range pkg.contracts {
+		for _, node := range c.nodes() {
+			result, _, err := s.rewriteIdentIn(pkg, node)
+			if err != nil {
+				return err
+			}
+			// The constraint or a type argument itself might be replaced.
+			if node == c.typeParamConstraint {
+				c.typeParamConstraint = result.(ast.Expr)
+			}
+			for _, ta := range c.typeArgs {
+				if node == ta.expr {
+					ta.expr = result.(ast.Expr)
 				}
-				return false
 			}
-			return true
-		})
+		}
 	}
 	return nil
 }
+
+// rewriteIdentIn converts TypeXXX in node, and returns the converted node and imports that the replacements need.
+//
+// Only identifiers that refer to top-level types are converted,
+// so fields, variables and local types that share their names are left alone.
+// A declaration is renamed, so its replacement must be an identifier.
+func (s *Spec) rewriteIdentIn(pkg *Package, node ast.Node) (ast.Node, []string, error) {
+	var (
+		imports []string
+		err     error
+	)
+	node = astutil.Apply(node, nil, func(c *astutil.Cursor) bool {
+		x, ok := c.Node().(*ast.Ident)
+		if !ok {
+			return true
+		}
+		obj, ok := pkg.objectOf(x).(*types.TypeName)
+		if !ok || obj.Parent() != pkg.types.Scope() {
+			return true
+		}
+		to, ok := s.TypeMap[obj.Name()]
+		if !ok {
+			return true
+		}
+		expr := s.typeExpr(obj.Name(), x.Pos())
+		if pkg.info.Defs[x] == obj {
+			ident, ok := expr.(*ast.Ident)
+			if !ok {
+				err = fmt.Errorf("spec %s: typeMap %s: %s is declared in the package, so it can only be renamed to an identifier", s.Name, obj.Name(), to.Expr)
+				return false
+			}
+			x.Name = ident.Name
+		} else {
+			replaceTypeExpr(c, expr)
+		}
+		imports = append(imports, to.Import...)
+		return true
+	})
+	return node, imports, err
+}
+
+var (
+	commentWord = regexp.MustCompile(`\[\w+(?:\.\w+)*\]|\w+`)
+	docLinkName = regexp.MustCompile(`^\w+(?:\.\w+){0,2}$`)
+)
+
+// rewriteComment converts TypeXXX mentioned in comment text to its replacement.
+//
+// A doc link like [TypeXXX] is kept as a link only if the replacement can still be linked.
+func (s *Spec) rewriteComment(text string) string {
+	return commentWord.ReplaceAllStringFunc(text, func(word string) string {
+		if word[0] != '[' {
+			to, ok := s.TypeMap[word]
+			if !ok {
+				return word
+			}
+			return to.Expr
+		}
+
+		link := word[1 : len(word)-1]
+		name, rest := link, ""
+		if i := strings.IndexByte(link, '.'); i >= 0 {
+			name, rest = link[:i], link[i:]
+		}
+		to, ok := s.TypeMap[name]
+		if !ok {
+			return word
+		}
+		if docLinkName.MatchString(to.Expr + rest) {
+			return "[" + to.Expr + rest + "]"
+		}
+		return to.Expr + rest
+	})
+}
+
+// renameDoc rewrites the name that a doc comment starts with, like "// New makes" or "// Package queue is",
+// so that the comment still describes the declaration after it is renamed.
+//
+// The name can follow an article like "A" or "The", and words are matched in full.
+func renameDoc(doc *ast.CommentGroup, prefix, from, to string) {
+	if doc == nil || len(doc.List) == 0 {
+		return
+	}
+	c := doc.List[0]
+	marker := c.Text[:2]
+	text := c.Text[2:]
+	body := strings.TrimLeft(text, " \t\n")
+	indent := text[:len(text)-len(body)]
+
+	var article string
+	if prefix == "" {
+		for _, word := range []string{"A ", "An ", "The "} {
+			if strings.HasPrefix(body, word) {
+				article = word
+				break
+			}
+		}
+	}
+	rest, ok := strings.CutPrefix(body, prefix+article+from)
+	if !ok || rest != "" && !strings.ContainsAny(rest[:1], " \t\n.,:;") {
+		return
+	}
+	c.Text = marker + indent + prefix + article + to + rest
+}
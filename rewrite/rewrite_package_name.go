@@ -1,21 +1,23 @@
 package rewrite
 
 import (
-	"errors"
-	"os"
+	"fmt"
 	"path/filepath"
 )
 
-// rewritePackageName sets current package name.
-func (s *Spec) rewritePackageName(pkg *Package) error {
+// rewritePackageName sets current package name, and rewrites the package doc comment to match.
+//
+// If the spec is local, it is localPkgName.
+func (s *Spec) rewritePackageName(pkg *Package, localPkgName string) error {
 	pkgName := filepath.Base(s.Name)
 	if s.Local {
-		pkgName = os.Getenv("GOPACKAGE")
+		pkgName = localPkgName
 		if pkgName == "" {
-			return errors.New("GOPACKAGE cannot be empty")
+			return fmt.Errorf("spec %s: package name of local specs cannot be empty", s.Name)
 		}
 	}
 	for _, node := range pkg.Files {
+		renameDoc(node.Doc, "Package ", node.Name.Name, pkgName)
 		node.Name.Name = pkgName
 	}
 	return nil
@@ -0,0 +1,197 @@
+package rewrite
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+)
+
+// Output is the formatted output of all specs.
+type Output struct {
+	// Files are generated files by path relative to Config.Dir.
+	Files map[string][]byte
+	// Dirs are directories that are replaced as a whole by files in them.
+	// They must be inside Config.Dir, and cannot be Config.Dir itself.
+	// Existing files in these directories that are not in Files are removed.
+	Dirs []string
+	// Remove are files that are generated before, but not anymore.
+	Remove []string
+}
+
+// Sink receives the output of all specs after every spec succeeds.
+type Sink interface {
+	Write(ctx context.Context, out *Output) error
+}
+
+// DiskSink writes output to files on disk relative to Dir.
+type DiskSink struct {
+	Dir string
+}
+
+func (sink *DiskSink) path(path string) string {
+	return filepath.Join(sink.Dir, path)
+}
+
+// Write writes output to disk.
+//
+// All files are first written next to their destinations, and then moved into place with rename.
+// If anything fails, what is already moved is moved back.
+func (sink *DiskSink) Write(ctx context.Context, out *Output) (err error) {
+	err = ctx.Err()
+	if err != nil {
+		return err
+	}
+	dirs := make(map[string]struct{})
+	for _, dir := range out.Dirs {
+		// A directory that is replaced must not take anything else with it.
+		err := checkOutputDir(dir, sink.Dir)
+		if err != nil {
+			return err
+		}
+		dirs[dir] = struct{}{}
+	}
+	var (
+		cleanup []string
+		undo    []func()
+	)
+	defer func() {
+		if err != nil {
+			for i := len(undo) - 1; i >= 0; i-- {
+				undo[i]()
+			}
+		}
+		for _, path := range cleanup {
+			os.RemoveAll(path)
+		}
+	}()
+
+	// Write new directories and files.
+	tmpPath := make(map[string]string)
+	for _, dir := range out.Dirs {
+		created, err := mkdirParent(sink.path(dir))
+		if err != nil {
+			return err
+		}
+		if created != "" {
+			undo = append(undo, func() { os.RemoveAll(created) })
+		}
+		tmp, err := tempName(sink.path(dir), func(name string) error {
+			return os.Mkdir(name, 0777)
+		})
+		if err != nil {
+			return err
+		}
+		cleanup = append(cleanup, tmp)
+		tmpPath[dir] = tmp
+	}
+	for _, path := range sortedKeys(out.Files) {
+		if _, ok := dirs[filepath.Dir(path)]; ok {
+			err := os.WriteFile(filepath.Join(tmpPath[filepath.Dir(path)], filepath.Base(path)), out.Files[path], 0666)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		created, err := mkdirParent(sink.path(path))
+		if err != nil {
+			return err
+		}
+		if created != "" {
+			undo = append(undo, func() { os.RemoveAll(created) })
+		}
+		tmp, err := tempName(sink.path(path), func(name string) error {
+			f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+			if err != nil {
+				return err
+			}
+			_, err = f.Write(out.Files[path])
+			if err1 := f.Close(); err == nil {
+				err = err1
+			}
+			if err != nil {
+				os.Remove(name)
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		cleanup = append(cleanup, tmp)
+		tmpPath[path] = tmp
+	}
+
+	// Move them into place.
+	for _, path := range append(out.Dirs, sortedKeys(out.Files)...) {
+		tmp, ok := tmpPath[path]
+		if !ok {
+			// This file is in a new directory.
+			continue
+		}
+		path := sink.path(path)
+		if _, err := os.Lstat(path); err == nil {
+			old, err := tempName(path, func(name string) error {
+				return os.Rename(path, name)
+			})
+			if err != nil {
+				return err
+			}
+			cleanup = append(cleanup, old)
+			undo = append(undo, func() { os.Rename(old, path) })
+		}
+		err := os.Rename(tmp, path)
+		if err != nil {
+			return err
+		}
+		undo = append(undo, func() { os.RemoveAll(path) })
+	}
+
+	// Remove files by moving them away.
+	for _, path := range out.Remove {
+		path := sink.path(path)
+		old, err := tempName(path, func(name string) error {
+			return os.Rename(path, name)
+		})
+		if err != nil {
+			return err
+		}
+		cleanup = append(cleanup, old)
+		undo = append(undo, func() { os.Rename(old, path) })
+	}
+	return nil
+}
+
+// mkdirParent creates missing parent directories of path,
+// and returns the top-most one that is created.
+func mkdirParent(path string) (string, error) {
+	var created string
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		created = dir
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	if created == "" {
+		return "", nil
+	}
+	return created, os.MkdirAll(filepath.Dir(path), 0777)
+}
+
+// tempName calls create with an unused hidden name next to path, and returns the name.
+func tempName(path string, create func(string) error) (string, error) {
+	for {
+		name := filepath.Join(filepath.Dir(path), fmt.Sprintf(".%s.%d", filepath.Base(path), rand.Uint32()))
+		err := create(name)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return name, nil
+	}
+}
@@ -0,0 +1,125 @@
+package rewrite
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// stage collects the output of all specs in memory.
+//
+// Nothing is written until every spec succeeds, and then its output is passed
+// to a sink at once, so a failed run leaves the tree untouched.
+// Paths are relative to dir. Specs can be added concurrently.
+type stage struct {
+	dir string
+
+	mu sync.Mutex
+	// dirs are directories that are replaced as a whole.
+	dirs   map[string]struct{}
+	files  map[string][]byte
+	remove map[string]struct{}
+	specs  []*stagedSpec
+}
+
+// stagedSpec is the output of one spec.
+type stagedSpec struct {
+	name   string
+	dir    string
+	files  []string
+	remove []string
+}
+
+func newStage(dir string) *stage {
+	return &stage{
+		dir:    dir,
+		dirs:   make(map[string]struct{}),
+		files:  make(map[string][]byte),
+		remove: make(map[string]struct{}),
+	}
+}
+
+// add stages files of a spec. If dir is not empty, it will be replaced by a new directory with these files.
+// Files in remove will be removed.
+func (st *stage) add(name, dir string, files map[string][]byte, remove []string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if dir != "" {
+		dir = filepath.Clean(dir)
+		if _, ok := st.dirs[dir]; ok {
+			return fmt.Errorf("%s is written by more than one spec", dir)
+		}
+		st.dirs[dir] = struct{}{}
+	}
+	for path, b := range files {
+		if _, ok := st.files[path]; ok {
+			return fmt.Errorf("%s is written by more than one spec", path)
+		}
+		st.files[path] = b
+	}
+	for _, path := range remove {
+		st.remove[path] = struct{}{}
+	}
+	st.specs = append(st.specs, &stagedSpec{
+		name:   name,
+		dir:    dir,
+		files:  sortedKeys(files),
+		remove: remove,
+	})
+	return nil
+}
+
+// path returns the path on disk of a staged path.
+func (st *stage) path(path string) string {
+	return filepath.Join(st.dir, path)
+}
+
+// snapshot returns a copy of staged files, and files to remove.
+func (st *stage) snapshot() (map[string][]byte, map[string]struct{}) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	files := make(map[string][]byte)
+	for path, b := range st.files {
+		files[path] = b
+	}
+	remove := make(map[string]struct{})
+	for path := range st.remove {
+		remove[path] = struct{}{}
+	}
+	return files, remove
+}
+
+// overlay returns staged files by absolute path.
+func (st *stage) overlay() map[string][]byte {
+	files, _ := st.snapshot()
+	overlay := make(map[string][]byte)
+	for path, b := range files {
+		overlay[st.path(path)] = b
+	}
+	return overlay
+}
+
+// output returns staged files.
+func (st *stage) output() *Output {
+	out := &Output{
+		Files:  make(map[string][]byte),
+		Dirs:   sortedKeys(st.dirs),
+		Remove: sortedKeys(st.remove),
+	}
+	for path, b := range st.files {
+		out.Files[path] = b
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,42 +1,154 @@
 package rewrite
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"go/ast"
-	"go/importer"
+	"go/build"
+	"go/parser"
 	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 )
 
-func (s *Spec) typeCheck(pkg *Package) error {
-	if s.Local {
-		return nil
+// typeCheck checks the rewritten package before it is written.
+//
+// If the spec is local, the package is checked together with existing files in its output directory,
+// so conflicts with the package that it is rewritten to are also found.
+// Files in stage are used in place of files on disk, and files of hidden specs are not used.
+// Type information of the rewritten package is kept in pkg.
+func (s *Spec) typeCheck(ctx context.Context, st *stage, pkg *Package, hidden map[string]struct{}) error {
+	var paths []string
+	for path := range pkg.Files {
+		paths = append(paths, path)
 	}
+	sort.Strings(paths)
 
 	var allFiles []*ast.File
-	for _, f := range pkg.Files {
-		allFiles = append(allFiles, f)
+	outputPath := make(map[string]string)
+	for _, path := range paths {
+		allFiles = append(allFiles, pkg.Files[path])
+		outputPath[path] = s.outputPath(path)
 	}
 	allFileSets := pkg.FileSet
 
+	if s.Local {
+		files, err := s.parseLocal(st, pkg, outputPath, hidden)
+		if err != nil {
+			return err
+		}
+		allFiles = append(allFiles, files...)
+	}
+
+	if len(pkg.contracts) > 0 && len(allFiles) > 0 {
+		f, err := s.contractFile(allFileSets, allFiles[0].Name.Name, pkg.contracts)
+		if err != nil {
+			return err
+		}
+		allFiles = append(allFiles, f)
+	}
+
 	var errType []error
 	conf := types.Config{
-		Importer: importer.For("source", nil),
+		Importer: newImporter(ctx, allFileSets, st.dir, st.overlay()),
 		Error: func(err error) {
-			// Ignore undeclared name error because we want developers to use this tool
-			// during development process.
-			if strings.HasPrefix(err.(types.Error).Msg, "undeclared name: ") {
+			terr := err.(types.Error)
+			pos := terr.Fset.Position(terr.Pos)
+			if pos.Filename == contractFilename {
+				// Errors of replacements are also found in the rewritten package.
 				return
 			}
-			errType = append(errType, err)
+			name, ok := outputPath[pos.Filename]
+			if !ok {
+				// Ignore undeclared name error in existing files because we want developers
+				// to use this tool during development process.
+				if strings.HasPrefix(terr.Msg, "undeclared name: ") || strings.HasPrefix(terr.Msg, "undefined: ") {
+					return
+				}
+			} else {
+				pos.Filename = name
+			}
+			errType = append(errType, fmt.Errorf("%s: %s", pos, terr.Msg))
 		},
 	}
-	conf.Check("", allFileSets, allFiles, nil)
-	if len(errType) > 0 {
-		for _, err := range errType {
-			fmt.Println(err)
+	info := newTypesInfo()
+	typesPkg, _ := conf.Check("", allFileSets, allFiles, info)
+	pkg.types = typesPkg
+	pkg.info = info
+	// Report unsatisfied contracts first because they explain type errors that follow.
+	errType = append(s.checkContract(typesPkg, pkg.contracts), errType...)
+	return errors.Join(errType...)
+}
+
+// parseLocal parses existing files in the output directory except those that will be overwritten.
+//
+// Files of hidden specs are skipped, because they might be rewritten concurrently.
+func (s *Spec) parseLocal(st *stage, pkg *Package, outputPath map[string]string, hidden map[string]struct{}) ([]*ast.File, error) {
+	var names []string
+	dir := s.outputDir()
+	buildP, err := build.ImportDir(st.path(dir), 0)
+	if err == nil {
+		names = buildP.GoFiles
+	} else if _, ok := err.(*build.NoGoError); !ok {
+		return nil, err
+	}
+
+	overwrite := make(map[string]struct{})
+	for _, name := range outputPath {
+		overwrite[name] = struct{}{}
+	}
+	isHidden := func(b []byte) bool {
+		o, err := ReadOrigin(b)
+		if err != nil {
+			return false
+		}
+		_, ok := hidden[o.Spec]
+		return ok
+	}
+	staged, remove := st.snapshot()
+	var files []*ast.File
+	for _, name := range names {
+		name := filepath.Join(dir, name)
+		if _, ok := overwrite[name]; ok {
+			continue
+		}
+		if _, ok := staged[name]; ok {
+			continue
+		}
+		if _, ok := remove[name]; ok {
+			continue
+		}
+		b, err := os.ReadFile(st.path(name))
+		if err != nil {
+			return nil, err
+		}
+		if isHidden(b) {
+			continue
+		}
+		f, err := parser.ParseFile(pkg.FileSet, name, b, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	for _, name := range sortedKeys(staged) {
+		if filepath.Dir(name) != dir {
+			continue
+		}
+		if _, ok := overwrite[name]; ok {
+			continue
+		}
+		if isHidden(staged[name]) {
+			continue
+		}
+		f, err := parser.ParseFile(pkg.FileSet, name, staged[name], parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
 		}
-		return errType[0]
+		files = append(files, f)
 	}
-	return nil
+	return files, nil
 }
@@ -0,0 +1,225 @@
+package rewrite
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// checkTypeMap checks that every replacement in typeMap is a type expression.
+func (s *Spec) checkTypeMap() error {
+	var errs []error
+	for _, key := range sortedKeys(s.TypeMap) {
+		expr, err := parser.ParseExpr(s.TypeMap[key].Expr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("spec %s: typeMap %s: %w", s.Name, key, err))
+			continue
+		}
+		if !isTypeExpr(expr) {
+			errs = append(errs, fmt.Errorf("spec %s: typeMap %s: %s is not a type", s.Name, key, s.TypeMap[key].Expr))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkTypeImports checks that every package-qualified type in typeMap is a type in one of its imports.
+func (s *Spec) checkTypeImports(importer types.Importer) error {
+	var errs []error
+	for _, key := range sortedKeys(s.TypeMap) {
+		to := s.TypeMap[key]
+		pkgs := make(map[string]*types.Package)
+		for _, im := range to.Import {
+			name, path := splitImport(im)
+			pkg, err := importer.Import(path)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("spec %s: typeMap %s: import %s: %w", s.Name, key, path, err))
+				continue
+			}
+			if name == "" {
+				name = pkg.Name()
+			}
+			pkgs[name] = pkg
+		}
+		expr, _ := parser.ParseExpr(to.Expr)
+		ast.Inspect(expr, func(n ast.Node) bool {
+			x, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			qual, ok := x.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			name := qual.Name + "." + x.Sel.Name
+			pkg, ok := pkgs[qual.Name]
+			if !ok {
+				errs = append(errs, fmt.Errorf("spec %s: typeMap %s: %s is used, but package %s is not in import", s.Name, key, name, qual.Name))
+				return false
+			}
+			obj := pkg.Scope().Lookup(x.Sel.Name)
+			if _, ok := obj.(*types.TypeName); ok && obj.Exported() {
+				return false
+			}
+			what := "not found"
+			switch obj.(type) {
+			case *types.TypeName:
+				what = "not exported"
+			case *types.Func:
+				what = "a function, not a type"
+			case *types.Var:
+				what = "a variable, not a type"
+			case *types.Const:
+				what = "a constant, not a type"
+			}
+			err := fmt.Errorf("spec %s: typeMap %s: %s is %s in %s", s.Name, key, name, what, pkg.Path())
+			if candidates := candidateTypes(pkg, qual.Name, x.Sel.Name); len(candidates) > 0 {
+				err = fmt.Errorf("%w; candidates: %s", err, strings.Join(candidates, ", "))
+			}
+			errs = append(errs, err)
+			return false
+		})
+	}
+	return errors.Join(errs...)
+}
+
+// candidateTypes returns exported types in pkg that are most similar to name.
+func candidateTypes(pkg *types.Package, qual, name string) []string {
+	const limit = 5
+	var names []string
+	for _, n := range pkg.Scope().Names() {
+		if _, ok := pkg.Scope().Lookup(n).(*types.TypeName); ok && token.IsExported(n) {
+			names = append(names, n)
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return editDistance(names[i], name) < editDistance(names[j], name)
+	})
+	if len(names) > limit {
+		names = names[:limit]
+	}
+	for i, n := range names {
+		names[i] = qual + "." + n
+	}
+	return names
+}
+
+// editDistance returns the levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// isTypeExpr returns true if expr is syntactically a type.
+func isTypeExpr(expr ast.Expr) bool {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return true
+	case *ast.SelectorExpr:
+		_, ok := x.X.(*ast.Ident)
+		return ok
+	case *ast.ParenExpr:
+		return isTypeExpr(x.X)
+	case *ast.StarExpr:
+		return isTypeExpr(x.X)
+	case *ast.ArrayType:
+		return isTypeExpr(x.Elt)
+	case *ast.MapType:
+		return isTypeExpr(x.Key) && isTypeExpr(x.Value)
+	case *ast.ChanType:
+		return isTypeExpr(x.Value)
+	case *ast.FuncType, *ast.StructType, *ast.InterfaceType:
+		return true
+	case *ast.IndexExpr:
+		return isTypeExpr(x.X) && isTypeExpr(x.Index)
+	case *ast.IndexListExpr:
+		for _, index := range x.Indices {
+			if !isTypeExpr(index) {
+				return false
+			}
+		}
+		return isTypeExpr(x.X)
+	}
+	return false
+}
+
+var posType = reflect.TypeOf(token.NoPos)
+
+// typeExpr parses the replacement of key in typeMap, and moves it to pos,
+// so that it is printed in place of the node that it replaces.
+//
+// Every replacement is parsed again because a node cannot be shared.
+func (s *Spec) typeExpr(key string, pos token.Pos) ast.Expr {
+	// The replacement is already checked by checkTypeMap.
+	expr, _ := parser.ParseExpr(s.TypeMap[key].Expr)
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		v := reflect.ValueOf(n).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Field(i); f.Type() == posType && f.Int() != int64(token.NoPos) {
+				f.SetInt(int64(pos))
+			}
+		}
+		return true
+	})
+	return expr
+}
+
+// replaceTypeExpr replaces the node at c with a type expression,
+// and adds parentheses if the expression would be parsed differently there.
+//
+// For example, a conversion to *T is (*T)(x), not *T(x).
+func replaceTypeExpr(c *astutil.Cursor, expr ast.Expr) {
+	if needParen(c, expr) {
+		expr = &ast.ParenExpr{Lparen: expr.Pos(), X: expr, Rparen: expr.End()}
+	}
+	c.Replace(expr)
+}
+
+func needParen(c *astutil.Cursor, expr ast.Expr) bool {
+	switch parent := c.Parent().(type) {
+	case *ast.CallExpr:
+		if c.Name() != "Fun" {
+			return false
+		}
+	case *ast.SelectorExpr:
+		if c.Name() != "X" {
+			return false
+		}
+	case *ast.ChanType:
+		// chan <-chan T is parsed as chan<- chan T.
+		ch, ok := expr.(*ast.ChanType)
+		return ok && parent.Dir == ast.SEND|ast.RECV && ch.Dir == ast.RECV
+	default:
+		return false
+	}
+	switch expr.(type) {
+	case *ast.StarExpr, *ast.FuncType, *ast.ChanType:
+		return true
+	}
+	return false
+}
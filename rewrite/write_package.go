@@ -1,54 +1,107 @@
 package rewrite
 
 import (
+	"bytes"
 	"fmt"
 	"go/format"
-	"go/token"
 	"os"
 	"path/filepath"
 )
 
-func (s *Spec) writePackage(pkg *Package) error {
-	fset := token.NewFileSet()
-	writeOutput := func() error {
-		for path, f := range pkg.Files {
-			path := filepath.Join(s.Name, filepath.Base(path))
-			if s.Local {
-				path = fmt.Sprintf("%s_%s", s.Name, filepath.Base(path))
-			}
-			// Print ast to file.
-			dest, err := os.Create(path)
-			if err != nil {
-				return err
-			}
-			defer dest.Close()
-
-			err = format.Node(dest, fset, f)
-			if err != nil {
-				return err
-			}
+// writePackage adds the package to stage, which is written to disk after all specs succeed.
+//
+// The header of each file describes configured, which is the spec before its typeMap is rewritten.
+// If the spec is not local, the output directory is replaced as a whole.
+// Otherwise, files that are generated by this spec before but not anymore are removed.
+func (s *Spec) writePackage(st *stage, pkg *Package, configured *Spec) error {
+	files := make(map[string][]byte)
+	for path, f := range pkg.Files {
+		// Print ast to file.
+		buf := bytes.NewBuffer(configured.origin(filepath.Base(path)).Header())
+		err := format.Node(buf, pkg.FileSet, f)
+		if err != nil {
+			return err
 		}
-		return nil
+		files[s.outputPath(path)] = buf.Bytes()
 	}
 
-	if s.Local {
-		return writeOutput()
+	if !s.Local {
+		return st.add(s.Name, s.outputDir(), files, nil)
 	}
 
-	err := os.RemoveAll(s.Name)
+	matches, err := filepath.Glob(st.path(s.outputPath("*.go")))
 	if err != nil {
 		return err
 	}
+	var remove []string
+	for _, match := range matches {
+		path, err := filepath.Rel(st.dir, match)
+		if err != nil {
+			return err
+		}
+		if _, ok := files[path]; ok {
+			continue
+		}
+		b, err := os.ReadFile(match)
+		if err != nil {
+			return err
+		}
+		o, err := ReadOrigin(b)
+		if err != nil || o.Spec != s.Name {
+			continue
+		}
+		remove = append(remove, path)
+	}
+	return st.add(s.Name, "", files, remove)
+}
 
-	err = os.MkdirAll(s.Name, 0777)
-	if err != nil {
-		return err
+// checkOutput checks that the spec is written inside Config.Dir.
+//
+// A non-local spec replaces its output directory as a whole, so it cannot be Config.Dir itself.
+func (s *Spec) checkOutput() error {
+	if s.Local {
+		if dir := s.outputDir(); !filepath.IsLocal(dir) {
+			return fmt.Errorf("spec %s: output %s must be inside the config directory", s.Name, dir)
+		}
+		return nil
 	}
-	err = writeOutput()
+	if filepath.Clean(s.Name) == "." {
+		return fmt.Errorf("spec %s: name cannot be the config directory, because its output is replaced as a whole", s.Name)
+	}
+	err := checkOutputDir(s.outputDir(), "the config directory")
 	if err != nil {
-		os.RemoveAll(s.Name)
-		return err
+		return fmt.Errorf("spec %s: %w", s.Name, err)
 	}
+	return nil
+}
 
+// checkOutputDir checks that a directory that is replaced as a whole is inside, but not the same as, the base directory,
+// which is described by base in errors.
+func checkOutputDir(dir, base string) error {
+	if !filepath.IsLocal(dir) {
+		return fmt.Errorf("output %s must be inside %s", dir, base)
+	}
+	if filepath.Clean(dir) == "." {
+		return fmt.Errorf("output %s cannot be %s, because it is replaced as a whole", dir, base)
+	}
 	return nil
 }
+
+// outputDir returns the directory that the spec is written to.
+func (s *Spec) outputDir() string {
+	switch {
+	case s.Output != "":
+		return filepath.Clean(s.Output)
+	case s.Local:
+		return "."
+	}
+	return s.Name
+}
+
+// outputPath returns where a file of the package that it is rewritten from should be written.
+func (s *Spec) outputPath(path string) string {
+	if s.Local {
+		return filepath.Join(s.outputDir(), fmt.Sprintf("%s_%s", s.Name, filepath.Base(path)))
+	}
+	return filepath.Join(s.outputDir(), filepath.Base(path))
+}